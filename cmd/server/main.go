@@ -2,20 +2,38 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"google.golang.org/grpc"
+
 	"github.com/akonyukov/echobox/internal/anticheat"
 	"github.com/akonyukov/echobox/internal/config"
+	"github.com/akonyukov/echobox/internal/grpcapi"
 	"github.com/akonyukov/echobox/internal/session"
 	"github.com/akonyukov/echobox/internal/terminal"
 	"github.com/akonyukov/echobox/internal/web"
 )
 
 func main() {
+	// If this process is the isolation helper PTY.New re-exec'd for a
+	// namespaced session, it never reaches the rest of main: it mounts the
+	// candidate's overlay root and execs into the real shell instead. Must
+	// run before anything else touches logging/config/network state.
+	terminal.RunIsolationHelper()
+
 	// Set up logging
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting SRE Interview Terminal...")
@@ -33,15 +51,40 @@ func main() {
 	log.Println(cfg.MOTD)
 
 	// Create session manager
-	sessionMgr, err := session.NewManager(cfg.OutputDir, cfg.CandidateName)
+	sessionMgr, err := session.NewManager(cfg.OutputDir, cfg.CandidateName, cfg.ReconnectWindow, cfg.ScrollbackLines)
 	if err != nil {
 		log.Fatalf("Failed to create session manager: %v", err)
 	}
 
 	log.Printf("Session created: %s in %s", sessionMgr.GetSession().ID, sessionMgr.GetSessionDir())
 
+	// Wire up the configured session artifact store (defaults to local disk)
+	if cfg.StorageBackend == "s3" {
+		s3Client, err := newS3Client(cfg)
+		if err != nil {
+			log.Fatalf("Failed to create S3 client: %v", err)
+		}
+		sessionMgr.SetStore(session.NewS3Store(s3Client, cfg.S3Bucket))
+		log.Printf("Session artifacts will be uploaded to s3://%s", cfg.S3Bucket)
+	}
+
+	// Wire up a live streaming sink (S3 / webhook / syslog), if configured
+	var sinks []terminal.RecorderSink
+	if cfg.RemoteSinkURL != "" {
+		sink, err := newRemoteSink(cfg)
+		if err != nil {
+			log.Fatalf("Failed to create remote sink: %v", err)
+		}
+		sinks = append(sinks, sink)
+		log.Printf("Streaming session events live to %s sink at %s", cfg.RemoteSinkType, cfg.RemoteSinkURL)
+
+		if s3Sink, ok := sink.(*terminal.S3Sink); ok {
+			sessionMgr.SetMetadata("remote_sink_s3_upload_id", s3Sink.UploadID())
+		}
+	}
+
 	// Create recorder
-	recorder, err := terminal.NewRecorder(sessionMgr.GetSessionDir(), cfg.FlushInterval)
+	recorder, err := terminal.NewRecorder(sessionMgr.GetSessionDir(), sessionMgr.GetSession().ID, cfg.FlushInterval, sinks, cfg.RemoteSinkBufferSize)
 	if err != nil {
 		log.Fatalf("Failed to create recorder: %v", err)
 	}
@@ -49,12 +92,67 @@ func main() {
 
 	log.Println("Recorder initialized")
 
+	// Create asciicast v2 writer alongside the scriptreplay-format recorder
+	cast, err := terminal.NewCastWriter(sessionMgr.GetSessionDir(), 80, 24, map[string]string{
+		"SHELL": cfg.Shell,
+		"TERM":  "xterm-256color",
+	})
+	if err != nil {
+		log.Fatalf("Failed to create cast writer: %v", err)
+	}
+	defer cast.Close()
+
+	log.Println("Cast writer initialized")
+
 	// Create anti-cheat detector
-	detector := anticheat.NewDetector(cfg.InputRateLimit)
+	cadenceThresholds := anticheat.CadenceThresholds{
+		WindowSize:            cfg.CadenceWindowSize,
+		MinCV:                 cfg.CadenceMinCV,
+		HistogramBucketMs:     cfg.CadenceHistogramBucketMs,
+		HistogramDominantRate: cfg.CadenceHistogramDominantRate,
+		DigraphMinStdDevMs:    cfg.CadenceDigraphMinStdDevMs,
+		DigraphMinOccurrences: cfg.CadenceDigraphMinOccurrences,
+	}
+	timingConfig := anticheat.TimingConfig{
+		RingSize:             cfg.TimingRingSize,
+		PasteMinChars:        cfg.TimingPasteMinChars,
+		PasteWindow:          cfg.TimingPasteWindow,
+		PasteMaxStdDevMs:     cfg.TimingPasteMaxStdDevMs,
+		BimodalMinSamples:    cfg.TimingBimodalMinSamples,
+		BimodalShortMs:       cfg.TimingBimodalShortMs,
+		BimodalLongMs:        cfg.TimingBimodalLongMs,
+		BimodalMinClusterPct: cfg.TimingBimodalMinClusterPct,
+	}
+	detector := anticheat.NewDetector(cfg.InputRateLimit, cadenceThresholds, timingConfig)
 	log.Printf("Anti-cheat detector initialized (rate limit: %d chars/sec)", cfg.InputRateLimit)
 
+	// Every saved report is exported in all built-in formats; signing is
+	// opt-in since it requires a key an operator has provisioned.
+	reportExporters := []anticheat.ReportExporter{
+		anticheat.JSONExporter{},
+		anticheat.HTMLExporter{},
+		anticheat.CSVExporter{},
+		anticheat.JUnitExporter{},
+	}
+
+	var reportChain *anticheat.ReportChain
+	if cfg.AnticheatSignKeyPath != "" {
+		signKey, err := anticheat.LoadSigningKey(cfg.AnticheatSignKeyPath)
+		if err != nil {
+			log.Printf("Warning: anti-cheat report signing disabled: %v", err)
+		} else {
+			reportChain = anticheat.NewReportChain(cfg.OutputDir, signKey)
+			log.Println("Anti-cheat report chain signing enabled")
+		}
+	}
+
 	// Create PTY
-	pty, err := terminal.New(cfg.Shell)
+	pty, err := terminal.New(cfg.Shell, cfg.ShellUID, terminal.IsolationConfig{
+		Namespaces: cfg.NamespaceIsolation,
+		MemLimitMB: cfg.MemLimitMB,
+		PidsMax:    cfg.PidsMax,
+		CPUQuota:   cfg.CPUQuota,
+	})
 	if err != nil {
 		log.Fatalf("Failed to create PTY: %v", err)
 	}
@@ -62,11 +160,69 @@ func main() {
 
 	log.Printf("PTY created, shell: %s", cfg.Shell)
 
+	// Emit a cast resize marker whenever the terminal is resized
+	pty.OnResize(cast.RecordResize)
+
+	// Mint a viewer token for read-only spectators and log the
+	// shareable watch URL
+	viewerToken, err := generateViewerToken()
+	if err != nil {
+		log.Fatalf("Failed to generate viewer token: %v", err)
+	}
+	log.Printf("Spectator URL: http://0.0.0.0:%d/watch/%s?token=%s",
+		cfg.Port, sessionMgr.GetSession().ID, viewerToken)
+	log.Printf("Spectator SSE URL: http://0.0.0.0:%d/events/%s?token=%s",
+		cfg.Port, sessionMgr.GetSession().ID, viewerToken)
+
 	// Create WebSocket handler with recorder and detector
-	wsHandler := web.NewWSHandler(pty, recorder, detector)
+	keepalive := web.KeepaliveConfig{
+		PingInterval:   cfg.WSPingInterval,
+		PongTimeout:    cfg.WSPongTimeout,
+		MaxMessageSize: cfg.WSMaxMessageSize,
+	}
+	compression := web.CompressionConfig{
+		Level:               cfg.WSCompressionLevel,
+		MinMessageSizeBytes: cfg.WSCompressionMinSize,
+	}
+	wsHandler := web.NewWSHandlerWithOptions(pty, recorder, cast, detector, sessionMgr.GetState(), viewerToken, keepalive, compression)
+	wsHandler.SetOriginPolicy(web.OriginPolicy{Origins: cfg.WSAllowedOrigins})
+	if cfg.WSRequireReconnectAuth {
+		wsHandler.SetAuthenticator(web.NewReconnectTokenAuthenticator(sessionMgr.GetState()))
+	}
+
+	// Watch the live anti-cheat verdict as the session runs. A proctor UI
+	// can poll detector.FreezeReport()/LiveUpdates() independently; this
+	// goroutine only handles the opt-in auto-termination policy.
+	go func() {
+		for update := range detector.LiveUpdates() {
+			log.Printf("Anti-cheat live verdict: %s (score: %.1f, trigger: %s)",
+				update.Verdict, update.SuspicionScore, update.Trigger)
+
+			if cfg.AutoTerminateSuspicious && update.Verdict == "SUSPICIOUS" {
+				log.Println("Auto-terminating session: live verdict reached SUSPICIOUS")
+				wsHandler.TriggerFinish()
+				return
+			}
+		}
+	}()
 
 	// Create HTTP server
-	server := web.New(cfg, wsHandler)
+	server := web.New(cfg, wsHandler, sessionMgr.GetSession().ID)
+
+	// Record every chaos injection to session metadata so post-hoc
+	// analysis can tell injected delays apart from candidate typing
+	server.SetChaosRecorder(func(cfg terminal.ChaosConfig) {
+		sessionMgr.SetMetadata("chaos", cfg)
+	})
+
+	// Create gRPC server exposing PTY output, keystrokes, and the
+	// anti-cheat report to external analytics pipelines
+	grpcServer := grpc.NewServer()
+	grpcServer.RegisterService(&grpcapi.ServiceDesc, grpcapi.NewServer(sessionMgr.GetSession().ID, sessionMgr.GetSessionDir(), pty))
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port: %v", err)
+	}
 
 	// Set up graceful shutdown
 	serverErrors := make(chan error, 1)
@@ -79,6 +235,14 @@ func main() {
 		serverErrors <- server.Start()
 	}()
 
+	// Start gRPC server in goroutine
+	go func() {
+		log.Printf("gRPC server listening on 0.0.0.0:%d", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Printf("gRPC server error: %v", err)
+		}
+	}()
+
 	// Wait for shutdown signal, session finish, or server error
 	select {
 	case err := <-serverErrors:
@@ -98,6 +262,9 @@ func main() {
 		if err := recorder.Close(); err != nil {
 			log.Printf("Error closing recorder: %v", err)
 		}
+		if err := cast.Close(); err != nil {
+			log.Printf("Error closing cast writer: %v", err)
+		}
 
 		// Extract commands and complete session
 		log.Println("Extracting commands...")
@@ -110,14 +277,12 @@ func main() {
 		if report, err := anticheat.AnalyzeSession(sessionMgr.GetSessionDir()); err != nil {
 			log.Printf("Error analyzing session: %v", err)
 		} else {
-			if err := anticheat.SaveReport(report, sessionMgr.GetSessionDir()); err != nil {
-				log.Printf("Error saving analysis report: %v", err)
-			} else {
-				log.Printf("Analysis: %s (confidence: %.2f)", report.Verdict, report.Confidence)
-			}
+			saveAnticheatReport(report, sessionMgr.GetSessionDir(), reportExporters, reportChain)
+			log.Printf("Analysis: %s (confidence: %.2f)", report.Verdict, report.Confidence)
 		}
 
 		log.Println("Finalizing session metadata...")
+		sessionMgr.SetMetadata("slice_chain_root", recorder.RootHash())
 		if err := sessionMgr.Complete(); err != nil {
 			log.Printf("Error completing session: %v", err)
 		}
@@ -133,6 +298,7 @@ func main() {
 		if err := server.Shutdown(ctx); err != nil {
 			log.Printf("Error during server shutdown: %v", err)
 		}
+		grpcServer.GracefulStop()
 
 		log.Printf("Session complete: %s", sessionMgr.GetSessionDir())
 		os.Exit(0)
@@ -155,6 +321,9 @@ func main() {
 		if err := recorder.Close(); err != nil {
 			log.Printf("Error closing recorder: %v", err)
 		}
+		if err := cast.Close(); err != nil {
+			log.Printf("Error closing cast writer: %v", err)
+		}
 
 		// Extract commands
 		log.Println("Extracting commands...")
@@ -167,16 +336,14 @@ func main() {
 		if report, err := anticheat.AnalyzeSession(sessionMgr.GetSessionDir()); err != nil {
 			log.Printf("Error analyzing session: %v", err)
 		} else {
-			if err := anticheat.SaveReport(report, sessionMgr.GetSessionDir()); err != nil {
-				log.Printf("Error saving analysis report: %v", err)
-			} else {
-				log.Printf("Analysis: %s (confidence: %.2f)", report.Verdict, report.Confidence)
-			}
+			saveAnticheatReport(report, sessionMgr.GetSessionDir(), reportExporters, reportChain)
+			log.Printf("Analysis: %s (confidence: %.2f)", report.Verdict, report.Confidence)
 		}
 
 		// Complete session with error status
 		log.Println("Finalizing session metadata...")
 		sessionMgr.GetSession().Status = "interrupted"
+		sessionMgr.SetMetadata("slice_chain_root", recorder.RootHash())
 		if err := sessionMgr.Complete(); err != nil {
 			log.Printf("Error completing session: %v", err)
 		}
@@ -192,8 +359,94 @@ func main() {
 		if err := server.Shutdown(ctx); err != nil {
 			log.Printf("Error during server shutdown: %v", err)
 		}
+		grpcServer.GracefulStop()
 
 		log.Printf("Graceful shutdown complete: %s", sessionMgr.GetSessionDir())
 
 	}
 }
+
+// saveAnticheatReport runs every configured exporter over report and, if
+// report chain signing is enabled, seals it into the tamper-evident
+// chain. Export/seal failures are logged but never block session
+// finalization - a missing HTML rendering shouldn't stop the candidate's
+// session from completing.
+func saveAnticheatReport(report *anticheat.AnalysisReport, sessionDir string, exporters []anticheat.ReportExporter, chain *anticheat.ReportChain) {
+	for _, exporter := range exporters {
+		if _, err := exporter.Export(report, sessionDir); err != nil {
+			log.Printf("Error exporting analysis report (%T): %v", exporter, err)
+		}
+	}
+
+	if chain != nil {
+		if _, err := chain.Seal(report, sessionDir); err != nil {
+			log.Printf("Error sealing analysis report chain: %v", err)
+		}
+	}
+}
+
+// generateViewerToken creates a random hex token used to authorize
+// read-only spectator connections on /watch.
+func generateViewerToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newS3Client builds an S3 client for the configured region, optionally
+// pointed at an S3-compatible endpoint (e.g. MinIO) instead of AWS.
+func newS3Client(cfg *config.Config) (*s3.Client, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(cfg.S3Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.S3Endpoint != "" {
+			o.BaseEndpoint = &cfg.S3Endpoint
+			o.UsePathStyle = true
+		}
+	}), nil
+}
+
+// newRemoteSink builds the terminal.RecorderSink selected by
+// cfg.RemoteSinkType, interpreting cfg.RemoteSinkURL according to that
+// type: a "s3://bucket/key" object path, a webhook URL, or a
+// "network://host:port" syslog server address (network is "tcp" or
+// "udp").
+func newRemoteSink(cfg *config.Config) (terminal.RecorderSink, error) {
+	switch cfg.RemoteSinkType {
+	case "s3":
+		parsed, err := url.Parse(cfg.RemoteSinkURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REMOTE_SINK_URL %q: %w", cfg.RemoteSinkURL, err)
+		}
+		bucket := parsed.Host
+		key := strings.TrimPrefix(parsed.Path, "/")
+
+		s3Client, err := newS3Client(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client for remote sink: %w", err)
+		}
+		return terminal.NewS3Sink(context.Background(), s3Client, bucket, key)
+
+	case "webhook":
+		return terminal.NewWebhookSink(cfg.RemoteSinkURL), nil
+
+	case "syslog":
+		parsed, err := url.Parse(cfg.RemoteSinkURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REMOTE_SINK_URL %q: %w", cfg.RemoteSinkURL, err)
+		}
+		network := parsed.Scheme
+		if network == "" {
+			network = "tcp"
+		}
+		return terminal.NewSyslogSink(network, parsed.Host)
+
+	default:
+		return nil, fmt.Errorf("unsupported REMOTE_SINK_TYPE %q", cfg.RemoteSinkType)
+	}
+}
@@ -0,0 +1,49 @@
+// Command verifychain walks a directory of echobox session reports and
+// checks that every sealed analysis.sig.json verifies against a public
+// key and links to the one before it, per anticheat.ReportChain.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/akonyukov/echobox/internal/anticheat"
+)
+
+func main() {
+	baseDir := flag.String("dir", "", "base sessions directory (the OUTPUT_DIR the server was run with)")
+	pubKeyHex := flag.String("pubkey", "", "hex-encoded Ed25519 public key to verify signatures against")
+	flag.Parse()
+
+	if *baseDir == "" || *pubKeyHex == "" {
+		fmt.Fprintln(os.Stderr, "usage: verifychain -dir <sessions dir> -pubkey <hex ed25519 public key>")
+		os.Exit(2)
+	}
+
+	links, err := anticheat.WalkChain(*baseDir, *pubKeyHex)
+	if err != nil {
+		log.Fatalf("Failed to walk chain: %v", err)
+	}
+
+	if len(links) == 0 {
+		fmt.Println("No signed reports found.")
+		return
+	}
+
+	broken := false
+	for _, link := range links {
+		status := "OK"
+		if !link.SignatureOK || !link.LinkOK {
+			status = "TAMPERED"
+			broken = true
+		}
+		fmt.Printf("%-60s verdict=%-20s signature=%v link=%v [%s]\n",
+			link.SessionDir, link.Verdict, link.SignatureOK, link.LinkOK, status)
+	}
+
+	if broken {
+		os.Exit(1)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,29 +14,56 @@ import (
 
 // TypingStats represents typing statistics
 type TypingStats struct {
-	TotalKeystrokes   int     `json:"total_keystrokes"`
-	SessionDuration   float64 `json:"session_duration_seconds"`
-	AverageWPM        float64 `json:"average_wpm"`
-	MedianWPM         float64 `json:"median_wpm"`
-	MaxWPM            float64 `json:"max_wpm"`
-	MinWPM            float64 `json:"min_wpm"`
-	WPMStdDev         float64 `json:"wpm_std_dev"`
+	TotalKeystrokes   int       `json:"total_keystrokes"`
+	SessionDuration   float64   `json:"session_duration_seconds"`
+	AverageWPM        float64   `json:"average_wpm"`
+	MedianWPM         float64   `json:"median_wpm"`
+	MaxWPM            float64   `json:"max_wpm"`
+	MinWPM            float64   `json:"min_wpm"`
+	WPMStdDev         float64   `json:"wpm_std_dev"`
 	TypingIntervals   []float64 `json:"-"` // Not exported to JSON
-	AnomaliesDetected int     `json:"anomalies_detected"`
+	AnomaliesDetected int       `json:"anomalies_detected"`
+
+	Dynamics KeystrokeDynamics `json:"keystroke_dynamics"`
+}
+
+// DigraphStat summarizes the flight-time distribution (release of the
+// first key to press of the second, approximated here as the interval
+// between consecutive keystroke arrivals since echobox's PTY pipeline
+// only observes when bytes were written, not raw key-down/key-up events)
+// for one two-character sequence.
+type DigraphStat struct {
+	Digraph  string  `json:"digraph"`
+	Count    int     `json:"count"`
+	MeanMS   float64 `json:"mean_ms"`
+	StdDevMS float64 `json:"std_dev_ms"`
+}
+
+// KeystrokeDynamics is the keystroke-dynamics biometric profile computed
+// from keystrokes.log: per-digraph flight-time statistics plus the two
+// derived signals generateVerdict uses to flag scripted replay and
+// operator swaps. The raw histogram is included so a reviewer can eyeball
+// the distribution instead of trusting the summary alone.
+type KeystrokeDynamics struct {
+	TopDigraphs            []DigraphStat `json:"top_digraphs"`
+	FlightTimeCV           float64       `json:"flight_time_cv"`
+	FlightTimeHistogramMS  []float64     `json:"flight_time_histogram_ms"`
+	DriftScore             float64       `json:"drift_score"`
+	InsufficientSampleSize bool          `json:"insufficient_sample_size"`
 }
 
 // AnalysisReport represents the complete analysis of a session
 type AnalysisReport struct {
-	SessionID         string                 `json:"session_id"`
-	CandidateName     string                 `json:"candidate_name"`
-	AnalysisTimestamp time.Time              `json:"analysis_timestamp"`
-	TypingStats       TypingStats            `json:"typing_stats"`
-	AntiCheatEvents   []Event                `json:"anticheat_events"`
-	EventSummary      map[string]int         `json:"event_summary"`
-	Verdict           string                 `json:"verdict"`
-	Confidence        float64                `json:"confidence_score"`
-	Flags             []string               `json:"flags"`
-	Recommendations   []string               `json:"recommendations"`
+	SessionID         string         `json:"session_id"`
+	CandidateName     string         `json:"candidate_name"`
+	AnalysisTimestamp time.Time      `json:"analysis_timestamp"`
+	TypingStats       TypingStats    `json:"typing_stats"`
+	AntiCheatEvents   []Event        `json:"anticheat_events"`
+	EventSummary      map[string]int `json:"event_summary"`
+	Verdict           string         `json:"verdict"`
+	Confidence        float64        `json:"confidence_score"`
+	Flags             []string       `json:"flags"`
+	Recommendations   []string       `json:"recommendations"`
 }
 
 // AnalyzeSession performs post-session typing pattern analysis
@@ -108,6 +136,9 @@ func analyzeKeystrokes(sessionDir string, sessionDuration float64) (*TypingStats
 	scanner := bufio.NewScanner(file)
 	lastTimestamp := int64(0)
 
+	var charTimestamps []int64
+	var chars []rune
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		parts := strings.SplitN(line, " ", 2)
@@ -124,11 +155,22 @@ func analyzeKeystrokes(sessionDir string, sessionDuration float64) (*TypingStats
 
 		if lastTimestamp > 0 {
 			interval := float64(timestamp-lastTimestamp) / 1000.0 // Convert to seconds
-			if interval > 0 && interval < 10 { // Ignore long pauses
+			if interval > 0 && interval < 10 {                    // Ignore long pauses
 				stats.TypingIntervals = append(stats.TypingIntervals, interval)
 			}
 		}
 
+		// Every character in this chunk is stamped with the chunk's
+		// arrival time: xterm.js normally sends one character per
+		// keystroke, but pasted or batched input arrives as a single
+		// multi-character chunk, so within-chunk flight time is zero.
+		if decoded, err := strconv.Unquote(parts[1]); err == nil {
+			for _, r := range decoded {
+				charTimestamps = append(charTimestamps, timestamp)
+				chars = append(chars, r)
+			}
+		}
+
 		lastTimestamp = timestamp
 	}
 
@@ -139,9 +181,188 @@ func analyzeKeystrokes(sessionDir string, sessionDuration float64) (*TypingStats
 	// Calculate WPM statistics
 	calculateWPMStats(stats)
 
+	stats.Dynamics = computeKeystrokeDynamics(charTimestamps, chars)
+
 	return stats, nil
 }
 
+const (
+	topDigraphCount         = 10
+	roboticUniformityCVMax  = 0.15
+	biometricDriftThreshold = 3.0 // diagonal-covariance-normalized distance
+)
+
+// flightSample is one measured digraph flight time, i.e. the interval
+// between two consecutive keystroke arrivals.
+type flightSample struct {
+	digraph string
+	ms      float64
+}
+
+// computeKeystrokeDynamics extracts per-digraph flight-time statistics,
+// the overall flight-time coefficient of variation, and a drift score
+// comparing the first third of the session against the last third.
+func computeKeystrokeDynamics(timestamps []int64, chars []rune) KeystrokeDynamics {
+	dyn := KeystrokeDynamics{}
+
+	if len(timestamps) < 3 {
+		dyn.InsufficientSampleSize = true
+		return dyn
+	}
+
+	samples := make([]flightSample, 0, len(timestamps)-1)
+
+	for i := 1; i < len(timestamps); i++ {
+		flight := float64(timestamps[i] - timestamps[i-1])
+		if flight < 0 || flight > 10000 { // ignore pauses, same bound as WPM analysis
+			continue
+		}
+		digraph := strings.ToLower(string([]rune{chars[i-1], chars[i]}))
+		samples = append(samples, flightSample{digraph: digraph, ms: flight})
+	}
+
+	if len(samples) < 2 {
+		dyn.InsufficientSampleSize = true
+		return dyn
+	}
+
+	dyn.FlightTimeHistogramMS = make([]float64, len(samples))
+	byDigraph := make(map[string][]float64)
+	for i, s := range samples {
+		dyn.FlightTimeHistogramMS[i] = s.ms
+		byDigraph[s.digraph] = append(byDigraph[s.digraph], s.ms)
+	}
+
+	dyn.FlightTimeCV = coefficientOfVariation(dyn.FlightTimeHistogramMS)
+	dyn.TopDigraphs = topDigraphStats(byDigraph, topDigraphCount)
+	dyn.DriftScore = driftScore(samples, dyn.TopDigraphs)
+
+	return dyn
+}
+
+// coefficientOfVariation returns stddev/mean, the standard measure of
+// relative dispersion used to tell "consistently scripted" timing apart
+// from natural human variance.
+func coefficientOfVariation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	avg := mean(values)
+	if avg == 0 {
+		return 0
+	}
+
+	variance := 0.0
+	for _, v := range values {
+		diff := v - avg
+		variance += diff * diff
+	}
+	variance /= float64(len(values))
+
+	return math.Sqrt(variance) / avg
+}
+
+// topDigraphStats returns the N most frequent digraphs with their mean
+// and standard deviation flight times, sorted by descending frequency.
+func topDigraphStats(byDigraph map[string][]float64, n int) []DigraphStat {
+	stats := make([]DigraphStat, 0, len(byDigraph))
+	for digraph, values := range byDigraph {
+		avg := mean(values)
+
+		variance := 0.0
+		for _, v := range values {
+			diff := v - avg
+			variance += diff * diff
+		}
+		variance /= float64(len(values))
+
+		stats = append(stats, DigraphStat{
+			Digraph:  digraph,
+			Count:    len(values),
+			MeanMS:   avg,
+			StdDevMS: math.Sqrt(variance),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Digraph < stats[j].Digraph
+	})
+
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// driftScore compares the digraph feature vector (mean flight time per
+// top digraph) computed on the first third of the session against the
+// last third, normalized per-feature by the session's own variance. This
+// is a diagonal-covariance approximation of a Mahalanobis distance: a
+// full covariance matrix isn't worth estimating from a single session's
+// worth of digraph samples.
+func driftScore(samples []flightSample, topDigraphs []DigraphStat) float64 {
+	if len(topDigraphs) == 0 || len(samples) < 6 {
+		return 0
+	}
+
+	third := len(samples) / 3
+	if third == 0 {
+		return 0
+	}
+	first := samples[:third]
+	last := samples[len(samples)-third:]
+
+	sumSq := 0.0
+	features := 0
+	for _, d := range topDigraphs {
+		firstVals := valuesFor(first, d.Digraph)
+		lastVals := valuesFor(last, d.Digraph)
+		if len(firstVals) == 0 || len(lastVals) == 0 {
+			continue
+		}
+
+		firstMean := mean(firstVals)
+		lastMean := mean(lastVals)
+		variance := d.StdDevMS * d.StdDevMS
+		if variance < 1.0 {
+			variance = 1.0 // floor to avoid blowing up on near-zero-variance digraphs
+		}
+
+		diff := firstMean - lastMean
+		sumSq += (diff * diff) / variance
+		features++
+	}
+
+	if features == 0 {
+		return 0
+	}
+	return math.Sqrt(sumSq / float64(features))
+}
+
+func valuesFor(samples []flightSample, digraph string) []float64 {
+	var values []float64
+	for _, s := range samples {
+		if s.digraph == digraph {
+			values = append(values, s.ms)
+		}
+	}
+	return values
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
 // calculateWPMStats calculates WPM metrics
 func calculateWPMStats(stats *TypingStats) {
 	if stats.SessionDuration == 0 || stats.TotalKeystrokes == 0 {
@@ -309,21 +530,23 @@ func generateVerdict(stats *TypingStats, events []Event) (string, float64, []str
 		suspicionScore += 15.0
 	}
 
-	// Generate verdict
-	verdict := "CLEAN"
-	confidence := 0.95
+	// Check keystroke-dynamics signals
+	if !stats.Dynamics.InsufficientSampleSize {
+		if stats.Dynamics.FlightTimeCV < roboticUniformityCVMax {
+			flags = append(flags, fmt.Sprintf("Robotic uniformity: digraph timing CV %.3f (< %.2f)",
+				stats.Dynamics.FlightTimeCV, roboticUniformityCVMax))
+			suspicionScore += 25.0
+		}
 
-	if suspicionScore >= 50 {
-		verdict = "SUSPICIOUS"
-		confidence = math.Min(suspicionScore/100.0, 0.95)
-	} else if suspicionScore >= 30 {
-		verdict = "REVIEW_RECOMMENDED"
-		confidence = 0.70
-	} else if suspicionScore >= 10 {
-		verdict = "MINOR_CONCERNS"
-		confidence = 0.85
+		if stats.Dynamics.DriftScore > biometricDriftThreshold {
+			flags = append(flags, fmt.Sprintf("Biometric drift: digraph profile distance %.2f (> %.2f), possible operator swap",
+				stats.Dynamics.DriftScore, biometricDriftThreshold))
+			suspicionScore += 20.0
+		}
 	}
 
+	verdict, confidence := verdictFromScore(suspicionScore)
+
 	if len(flags) == 0 {
 		flags = append(flags, "No anomalies detected")
 	}
@@ -331,6 +554,22 @@ func generateVerdict(stats *TypingStats, events []Event) (string, float64, []str
 	return verdict, confidence, flags
 }
 
+// verdictFromScore maps a cumulative suspicion score to a verdict and
+// confidence. Shared by the batch generateVerdict and LiveAnalyzer so the
+// two never drift apart on what counts as "suspicious".
+func verdictFromScore(suspicionScore float64) (string, float64) {
+	switch {
+	case suspicionScore >= 50:
+		return "SUSPICIOUS", math.Min(suspicionScore/100.0, 0.95)
+	case suspicionScore >= 30:
+		return "REVIEW_RECOMMENDED", 0.70
+	case suspicionScore >= 10:
+		return "MINOR_CONCERNS", 0.85
+	default:
+		return "CLEAN", 0.95
+	}
+}
+
 // generateRecommendations provides recommendations based on analysis
 func generateRecommendations(events []Event, stats *TypingStats) []string {
 	recommendations := make([]string, 0)
@@ -366,20 +605,11 @@ func generateRecommendations(events []Event, stats *TypingStats) []string {
 	return recommendations
 }
 
-// SaveReport saves the analysis report to a JSON file with restricted permissions
+// SaveReport saves the analysis report as JSON with restricted
+// permissions. It is a thin wrapper over JSONExporter kept for callers
+// that only want the one canonical format; see ReportExporter for the
+// other built-in formats and ReportChain for signed, tamper-evident saves.
 func SaveReport(report *AnalysisReport, sessionDir string) error {
-	reportPath := fmt.Sprintf("%s/analysis.json", sessionDir)
-
-	data, err := json.MarshalIndent(report, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal report: %w", err)
-	}
-
-	// Create with read-only permissions (root only access)
-	// Mode 0400 = owner (root) read-only, no access for others
-	if err := os.WriteFile(reportPath, data, 0400); err != nil {
-		return fmt.Errorf("failed to write report: %w", err)
-	}
-
-	return nil
+	_, err := (JSONExporter{}).Export(report, sessionDir)
+	return err
 }
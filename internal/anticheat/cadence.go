@@ -0,0 +1,234 @@
+package anticheat
+
+import (
+	"math"
+	"time"
+)
+
+// CadenceThresholds configures the keystroke-dynamics heuristics in
+// cadenceTracker. The defaults come from typical human-typing-cadence
+// studies, not from this project's own session data.
+type CadenceThresholds struct {
+	WindowSize int // rolling number of inter-key intervals to judge cadence over
+
+	MinCV float64 // coefficient of variation below this looks mechanically regular
+
+	HistogramBucketMs     float64 // histogram bucket width, in ms
+	HistogramDominantRate float64 // fraction of samples in one bucket that flags robotic cadence
+
+	DigraphMinStdDevMs    float64 // below this, a repeated bigram's timing looks replayed
+	DigraphMinOccurrences int     // minimum repeats of a bigram before judging its stddev
+}
+
+// DefaultCadenceThresholds returns the thresholds used when
+// config.Config doesn't override them.
+func DefaultCadenceThresholds() CadenceThresholds {
+	return CadenceThresholds{
+		WindowSize:            200,
+		MinCV:                 0.35,
+		HistogramBucketMs:     10,
+		HistogramDominantRate: 0.70,
+		DigraphMinStdDevMs:    15,
+		DigraphMinOccurrences: 3,
+	}
+}
+
+// cadenceTracker maintains a rolling window of inter-key intervals
+// (flight time; per-key dwell time isn't observable from server-side PTY
+// input) and per-bigram interval history, to tell genuine human typing
+// apart from robotic or replayed input.
+type cadenceTracker struct {
+	thresholds CadenceThresholds
+
+	intervals    []float64 // rolling window, most recent WindowSize samples
+	lastChar     byte
+	haveLastChar bool
+
+	digraphIntervals map[string][]float64
+}
+
+func newCadenceTracker(thresholds CadenceThresholds) *cadenceTracker {
+	return &cadenceTracker{
+		thresholds:       thresholds,
+		digraphIntervals: make(map[string][]float64),
+	}
+}
+
+// record folds one CheckInput call's bytes into the tracker and returns
+// any cadence events the updated window now supports. elapsed is the time
+// since the previous call; when data has more than one byte (a paste or a
+// fast burst), that time is spread evenly across its bytes rather than
+// treated as a single near-zero interval.
+func (c *cadenceTracker) record(data []byte, elapsed time.Duration) []Event {
+	if len(data) == 0 {
+		return nil
+	}
+
+	perChar := float64(elapsed.Milliseconds()) / float64(len(data))
+
+	for _, b := range data {
+		if c.haveLastChar {
+			c.addInterval(perChar)
+			c.addDigraph(c.lastChar, b, perChar)
+		}
+		c.lastChar = b
+		c.haveLastChar = true
+	}
+
+	return c.evaluate()
+}
+
+func (c *cadenceTracker) addInterval(intervalMs float64) {
+	c.intervals = append(c.intervals, intervalMs)
+	if len(c.intervals) > c.thresholds.WindowSize {
+		c.intervals = c.intervals[len(c.intervals)-c.thresholds.WindowSize:]
+	}
+}
+
+func (c *cadenceTracker) addDigraph(prev, cur byte, intervalMs float64) {
+	if !isLetter(prev) || !isLetter(cur) {
+		return
+	}
+	bigram := string([]byte{prev, cur})
+	c.digraphIntervals[bigram] = append(c.digraphIntervals[bigram], intervalMs)
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// evaluate runs the current window through each heuristic. It only fires
+// once the window is full, so a session's first few keystrokes can't
+// trip a cadence verdict off of a tiny, noisy sample.
+func (c *cadenceTracker) evaluate() []Event {
+	var events []Event
+
+	if len(c.intervals) < c.thresholds.WindowSize {
+		return events
+	}
+
+	mean, stddev := meanStdDev(c.intervals)
+	cv := 0.0
+	if mean > 0 {
+		cv = stddev / mean
+	}
+
+	if cv < c.thresholds.MinCV {
+		events = append(events, c.event(SeverityWarning, "robotic_cadence",
+			"Keystroke cadence looks mechanically regular", map[string]interface{}{
+				"coefficient_of_variation": cv,
+				"mean_interval_ms":         mean,
+				"stddev_interval_ms":       stddev,
+				"window_size":              len(c.intervals),
+			}))
+	}
+
+	if bucket, ratio := c.dominantBucketRate(); ratio > c.thresholds.HistogramDominantRate {
+		events = append(events, c.event(SeverityWarning, "robotic_cadence",
+			"Keystroke intervals are concentrated in a single histogram bucket", map[string]interface{}{
+				"dominant_bucket_ms": bucket,
+				"dominant_rate":      ratio,
+				"bucket_width_ms":    c.thresholds.HistogramBucketMs,
+			}))
+	}
+
+	if bigram, stddev, ok := c.suspiciousDigraph(); ok {
+		events = append(events, c.event(SeverityCritical, "replay_suspected",
+			"Repeated keystroke timing looks replayed rather than typed", map[string]interface{}{
+				"bigram":             bigram,
+				"stddev_interval_ms": stddev,
+				"occurrences":        len(c.digraphIntervals[bigram]),
+			}))
+	}
+
+	return events
+}
+
+// dominantBucketRate buckets the current window at HistogramBucketMs
+// resolution and returns the most populous bucket's lower bound and the
+// fraction of all samples it holds.
+func (c *cadenceTracker) dominantBucketRate() (float64, float64) {
+	buckets := make(map[float64]int)
+	for _, v := range c.intervals {
+		bucket := math.Floor(v/c.thresholds.HistogramBucketMs) * c.thresholds.HistogramBucketMs
+		buckets[bucket]++
+	}
+
+	var dominant float64
+	var maxCount int
+	for bucket, count := range buckets {
+		if count > maxCount {
+			maxCount = count
+			dominant = bucket
+		}
+	}
+
+	return dominant, float64(maxCount) / float64(len(c.intervals))
+}
+
+// suspiciousDigraph returns the first repeated bigram (seen at least
+// DigraphMinOccurrences times) whose interval stddev is low enough to
+// look replayed rather than typed.
+func (c *cadenceTracker) suspiciousDigraph() (string, float64, bool) {
+	for bigram, intervals := range c.digraphIntervals {
+		if len(intervals) < c.thresholds.DigraphMinOccurrences {
+			continue
+		}
+		_, stddev := meanStdDev(intervals)
+		if stddev < c.thresholds.DigraphMinStdDevMs {
+			return bigram, stddev, true
+		}
+	}
+	return "", 0, false
+}
+
+// snapshot reports the current cadence metrics for GetStatistics, so a
+// reviewer can see per-session typing-cadence metrics without waiting for
+// a robotic_cadence/replay_suspected event to fire.
+func (c *cadenceTracker) snapshot() map[string]interface{} {
+	mean, stddev := meanStdDev(c.intervals)
+	cv := 0.0
+	if mean > 0 {
+		cv = stddev / mean
+	}
+
+	return map[string]interface{}{
+		"sample_count":             len(c.intervals),
+		"mean_interval_ms":         mean,
+		"stddev_interval_ms":       stddev,
+		"coefficient_of_variation": cv,
+		"tracked_digraphs":         len(c.digraphIntervals),
+	}
+}
+
+func (c *cadenceTracker) event(severity Severity, eventType, description string, data map[string]interface{}) Event {
+	return Event{
+		Timestamp:   time.Now(),
+		Severity:    severity,
+		Type:        eventType,
+		Description: description,
+		Data:        data,
+	}
+}
+
+// meanStdDev returns the population mean and standard deviation of values.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		d := v - mean
+		variance += d * d
+	}
+	variance /= float64(len(values))
+
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,256 @@
+package anticheat
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// chainStateFile tracks the hash of the most recently sealed report
+// across every session under a Manager's base directory, so the chain
+// survives process restarts between interview sessions.
+const chainStateFile = ".anticheat_chain_state"
+
+// envelopeFilename is the signed, chained sibling of analysis.json.
+const envelopeFilename = "analysis.sig.json"
+
+// sessionDirTimestamp matches the start timestamp session.Manager embeds
+// in each session directory name.
+var sessionDirTimestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}_\d{2}-\d{2}-\d{2}`)
+
+// ReportEnvelope wraps an AnalysisReport with the integrity metadata that
+// lets a reviewer detect after-the-fact tampering or reordering: the
+// report's own content hash, the hash of the previous report in the
+// chain, and an Ed25519 signature over both.
+type ReportEnvelope struct {
+	Report    *AnalysisReport `json:"report"`
+	Hash      string          `json:"hash"`
+	PrevHash  string          `json:"prev_hash"`
+	Signature string          `json:"signature"`
+	PublicKey string          `json:"public_key"`
+}
+
+// ReportChain seals AnalysisReports with a content hash, an Ed25519
+// signature, and a link to the previous report sealed under the same
+// base directory, so a directory of session reports forms a hash chain.
+type ReportChain struct {
+	baseDir string
+	privKey ed25519.PrivateKey
+}
+
+// NewReportChain creates a ReportChain rooted at baseDir (the same
+// directory session.Manager creates candidate session directories
+// under), using privKey to sign every sealed report.
+func NewReportChain(baseDir string, privKey ed25519.PrivateKey) *ReportChain {
+	return &ReportChain{baseDir: baseDir, privKey: privKey}
+}
+
+// LoadSigningKey reads a raw 64-byte Ed25519 private key (seed||pubkey,
+// the format ed25519.GenerateKey returns) hex-encoded from path.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not valid hex: %w", err)
+	}
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key must be %d bytes, got %d", ed25519.PrivateKeySize, len(raw))
+	}
+
+	return ed25519.PrivateKey(raw), nil
+}
+
+// GenerateSigningKey creates a new Ed25519 key pair and writes the
+// private key hex-encoded to path, for operators bootstrapping a fresh
+// report chain.
+func GenerateSigningKey(path string) (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(priv)), 0400); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return priv, nil
+}
+
+// Seal hashes, signs, and chains report, writing the envelope to
+// sessionDir/analysis.sig.json alongside the plain analysis.json.
+func (c *ReportChain) Seal(report *AnalysisReport, sessionDir string) (*ReportEnvelope, error) {
+	canonical, err := marshalReportJSON(report)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	hash := hex.EncodeToString(sum[:])
+
+	prevHash, err := c.lastHash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chain state: %w", err)
+	}
+
+	signature := ed25519.Sign(c.privKey, []byte(hash+prevHash))
+	pubKey := c.privKey.Public().(ed25519.PublicKey)
+
+	envelope := &ReportEnvelope{
+		Report:    report,
+		Hash:      hash,
+		PrevHash:  prevHash,
+		Signature: hex.EncodeToString(signature),
+		PublicKey: hex.EncodeToString(pubKey),
+	}
+
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report envelope: %w", err)
+	}
+
+	path := filepath.Join(sessionDir, envelopeFilename)
+	if err := os.WriteFile(path, data, 0400); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := c.advance(hash); err != nil {
+		return nil, fmt.Errorf("failed to advance chain state: %w", err)
+	}
+
+	return envelope, nil
+}
+
+func (c *ReportChain) statePath() string {
+	return filepath.Join(c.baseDir, chainStateFile)
+}
+
+func (c *ReportChain) lastHash() (string, error) {
+	data, err := os.ReadFile(c.statePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (c *ReportChain) advance(hash string) error {
+	return os.WriteFile(c.statePath(), []byte(hash), 0600)
+}
+
+// VerifyReport loads a sealed report envelope and checks that its content
+// hash matches the embedded report and that the signature over
+// hash+prevHash verifies against pubKeyHex (hex-encoded Ed25519 public
+// key). It does not check linkage against a previous report - see
+// WalkChain for verifying an entire directory's ordering.
+func VerifyReport(path string, pubKeyHex string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read envelope: %w", err)
+	}
+
+	var envelope ReportEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return false, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+
+	pubKey, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return false, fmt.Errorf("public key is not valid hex: %w", err)
+	}
+
+	canonical, err := marshalReportJSON(envelope.Report)
+	if err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256(canonical)
+	if hex.EncodeToString(sum[:]) != envelope.Hash {
+		return false, nil
+	}
+
+	signature, err := hex.DecodeString(envelope.Signature)
+	if err != nil {
+		return false, fmt.Errorf("signature is not valid hex: %w", err)
+	}
+
+	return ed25519.Verify(ed25519.PublicKey(pubKey), []byte(envelope.Hash+envelope.PrevHash), signature), nil
+}
+
+// ChainLink is one verified (or broken) step of a directory walk - see
+// WalkChain.
+type ChainLink struct {
+	SessionDir  string
+	Verdict     string
+	SignatureOK bool
+	LinkOK      bool // PrevHash matches the previous link's Hash
+}
+
+// WalkChain scans baseDir for session directories containing an
+// analysis.sig.json, orders them by session start time (the directory
+// name's embedded timestamp, which session.Manager always produces in
+// sortable form), and checks both each envelope's signature and that
+// consecutive envelopes are actually linked. A gap or reordering in
+// LinkOK is exactly the "report re-run with different thresholds" or
+// "session deleted" tampering this chain defends against.
+func WalkChain(baseDir string, pubKeyHex string) ([]ChainLink, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", baseDir, err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(baseDir, entry.Name(), envelopeFilename)); err == nil {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	// session.Manager names dirs "<candidate>_<start>_<id>", so order by the
+	// embedded start timestamp rather than by the whole name.
+	sort.Strings(dirs)
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return sessionDirTimestamp.FindString(dirs[i]) < sessionDirTimestamp.FindString(dirs[j])
+	})
+
+	var links []ChainLink
+	prevHash := ""
+	for _, dir := range dirs {
+		sessionDir := filepath.Join(baseDir, dir)
+		path := filepath.Join(sessionDir, envelopeFilename)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var envelope ReportEnvelope
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		ok, err := VerifyReport(path, pubKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify %s: %w", path, err)
+		}
+
+		links = append(links, ChainLink{
+			SessionDir:  sessionDir,
+			Verdict:     envelope.Report.Verdict,
+			SignatureOK: ok,
+			LinkOK:      envelope.PrevHash == prevHash,
+		})
+		prevHash = envelope.Hash
+	}
+
+	return links, nil
+}
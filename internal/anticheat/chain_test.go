@@ -0,0 +1,135 @@
+package anticheat
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport(sessionID, verdict string) *AnalysisReport {
+	return &AnalysisReport{
+		SessionID:         sessionID,
+		CandidateName:     "candidate",
+		AnalysisTimestamp: time.Unix(0, 0).UTC(),
+		Verdict:           verdict,
+		EventSummary:      map[string]int{},
+	}
+}
+
+// TestReportChainSealThenVerify checks that a report Seal produces
+// verifies successfully against the signing key's public half.
+func TestReportChainSealThenVerify(t *testing.T) {
+	baseDir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	pubKeyHex := hex.EncodeToString(priv.Public().(ed25519.PublicKey))
+
+	chain := NewReportChain(baseDir, priv)
+	sessionDir := filepath.Join(baseDir, "2024-01-01T00-00-00-session")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	envelope, err := chain.Seal(testReport("s1", "clean"), sessionDir)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if envelope.PrevHash != "" {
+		t.Fatalf("first Seal in a fresh chain should have empty PrevHash, got %q", envelope.PrevHash)
+	}
+
+	path := filepath.Join(sessionDir, envelopeFilename)
+	ok, err := VerifyReport(path, pubKeyHex)
+	if err != nil {
+		t.Fatalf("VerifyReport: %v", err)
+	}
+	if !ok {
+		t.Fatalf("VerifyReport should succeed for a freshly sealed envelope")
+	}
+}
+
+// TestReportChainLinksConsecutiveSeals checks that a second Seal under
+// the same baseDir references the first's hash as PrevHash, and that
+// WalkChain reports both links as intact.
+func TestReportChainLinksConsecutiveSeals(t *testing.T) {
+	baseDir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	pubKeyHex := hex.EncodeToString(priv.Public().(ed25519.PublicKey))
+	chain := NewReportChain(baseDir, priv)
+
+	dir1 := filepath.Join(baseDir, "2024-01-01T00-00-00-session")
+	dir2 := filepath.Join(baseDir, "2024-01-02T00-00-00-session")
+	os.MkdirAll(dir1, 0755)
+	os.MkdirAll(dir2, 0755)
+
+	env1, err := chain.Seal(testReport("s1", "clean"), dir1)
+	if err != nil {
+		t.Fatalf("Seal 1: %v", err)
+	}
+	env2, err := chain.Seal(testReport("s2", "flagged"), dir2)
+	if err != nil {
+		t.Fatalf("Seal 2: %v", err)
+	}
+	if env2.PrevHash != env1.Hash {
+		t.Fatalf("second envelope's PrevHash = %q, want first envelope's Hash %q", env2.PrevHash, env1.Hash)
+	}
+
+	links, err := WalkChain(baseDir, pubKeyHex)
+	if err != nil {
+		t.Fatalf("WalkChain: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("WalkChain returned %d links, want 2", len(links))
+	}
+	for _, link := range links {
+		if !link.SignatureOK || !link.LinkOK {
+			t.Fatalf("link %+v should have SignatureOK and LinkOK both true", link)
+		}
+	}
+}
+
+// TestReportChainDetectsTamperedContent checks that modifying a sealed
+// report's content after the fact (without re-signing) fails VerifyReport,
+// since the embedded Hash is derived from the report's canonical JSON.
+func TestReportChainDetectsTamperedContent(t *testing.T) {
+	baseDir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	pubKeyHex := hex.EncodeToString(priv.Public().(ed25519.PublicKey))
+	chain := NewReportChain(baseDir, priv)
+
+	sessionDir := filepath.Join(baseDir, "2024-01-01T00-00-00-session")
+	os.MkdirAll(sessionDir, 0755)
+	if _, err := chain.Seal(testReport("s1", "clean"), sessionDir); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	path := filepath.Join(sessionDir, envelopeFilename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	tampered := []byte(strings.Replace(string(data), `"verdict": "clean"`, `"verdict": "flagged"`, 1))
+	if err := os.WriteFile(path, tampered, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ok, err := VerifyReport(path, pubKeyHex)
+	if err != nil {
+		t.Fatalf("VerifyReport: %v", err)
+	}
+	if ok {
+		t.Fatalf("VerifyReport should fail once the report content no longer matches its signed hash")
+	}
+}
@@ -9,11 +9,20 @@ import (
 	"github.com/akonyukov/echobox/internal/security"
 )
 
+// limiterKey identifies this process's single candidate session within
+// the keyed rate limiter. echobox runs one session per process, so there
+// is only ever one key in play here; the keying exists for a future
+// multi-tenant daemon, not for anything this Detector needs to vary.
+const limiterKey = "session"
+
 // Detector performs real-time anti-cheat detection
 type Detector struct {
-	rateLimiter   *security.RateLimiter
+	rateLimiter   *security.Limiter[string]
 	burstDetector *security.BurstDetector
 	logger        *Logger
+	live          *LiveAnalyzer
+	cadence       *cadenceTracker
+	timing        *TimingAnalyzer
 
 	// Typing pattern tracking
 	keystrokeCount   int
@@ -24,11 +33,14 @@ type Detector struct {
 }
 
 // NewDetector creates a new anti-cheat detector
-func NewDetector(maxCharsPerSecond int) *Detector {
+func NewDetector(maxCharsPerSecond int, cadenceThresholds CadenceThresholds, timingConfig TimingConfig) *Detector {
 	return &Detector{
-		rateLimiter:      security.NewRateLimiter(maxCharsPerSecond),
+		rateLimiter:      security.NewLimiter[string](maxCharsPerSecond),
 		burstDetector:    security.NewBurstDetector(30, 100*time.Millisecond), // 30 chars in 100ms
 		logger:           NewLogger(),
+		live:             NewLiveAnalyzer(DefaultLiveThresholds()),
+		cadence:          newCadenceTracker(cadenceThresholds),
+		timing:           newTimingAnalyzer(timingConfig),
 		sessionStartTime: time.Now(),
 		lastKeystroke:    time.Now(),
 	}
@@ -49,8 +61,10 @@ func (d *Detector) CheckInput(data []byte) (bool, []Event) {
 	timeSinceLastKey := now.Sub(d.lastKeystroke)
 	d.lastKeystroke = now
 
+	d.live.RecordKeystroke(data)
+
 	// Check rate limit
-	allowed, currentRate, rateViolation := d.rateLimiter.CheckInput(length)
+	allowed, currentRate, rateViolation := d.rateLimiter.Check(limiterKey, length)
 	if rateViolation {
 		event := d.logger.LogRapidInput(currentRate, length)
 		violations = append(violations, *event)
@@ -76,6 +90,34 @@ func (d *Detector) CheckInput(data []byte) (bool, []Event) {
 		violations = append(violations, *event)
 	}
 
+	// Keystroke-dynamics: coefficient of variation, interval histogram,
+	// and digraph-consistency checks (see cadence.go)
+	for _, cadenceEvent := range d.cadence.record(data, timeSinceLastKey) {
+		event := d.logger.LogCustomEvent(cadenceEvent.Severity, cadenceEvent.Type, cadenceEvent.Description, cadenceEvent.Data)
+		violations = append(violations, *event)
+	}
+
+	// Ring-buffer keystroke timing: variance-gated paste detection and
+	// bimodal (type-pause-type) interval distribution (see timing.go)
+	findings := d.timing.Record(data)
+	if findings.PasteSuspected {
+		event := d.logger.LogPasteAttempt("timing_variance", findings.BurstSize)
+		violations = append(violations, *event)
+		log.Printf("Anti-cheat: Low-variance burst detected - %d chars, stddev %.2fms",
+			findings.BurstSize, findings.BurstStdDevMs)
+	}
+	if findings.BimodalAnomaly {
+		event := d.logger.LogTypingAnomaly("bimodal_interval_distribution", map[string]interface{}{
+			"short_cluster_mean_ms": findings.ShortClusterMeanMs,
+			"long_cluster_mean_ms":  findings.LongClusterMeanMs,
+		})
+		violations = append(violations, *event)
+	}
+
+	for _, violation := range violations {
+		d.live.RecordEvent(violation)
+	}
+
 	return allowed, violations
 }
 
@@ -114,9 +156,23 @@ func (d *Detector) RecordClientEvent(eventType string, data map[string]interface
 	}
 
 	event := d.logger.LogCustomEvent(severity, eventType, description, data)
+	d.live.RecordEvent(*event)
 	return event
 }
 
+// LiveUpdates returns the channel of incremental verdicts produced as the
+// session runs, so a proctor UI can watch flags accumulate live instead of
+// waiting for the post-session AnalyzeSession report.
+func (d *Detector) LiveUpdates() <-chan VerdictUpdate {
+	return d.live.Updates()
+}
+
+// FreezeReport returns a full AnalysisReport from the session so far,
+// without waiting for the session to end.
+func (d *Detector) FreezeReport() *AnalysisReport {
+	return d.live.Freeze()
+}
+
 // GetStatistics returns session statistics
 func (d *Detector) GetStatistics() map[string]interface{} {
 	d.mu.Lock()
@@ -129,15 +185,19 @@ func (d *Detector) GetStatistics() map[string]interface{} {
 		wpm = (float64(d.keystrokeCount) / 5.0) / (duration / 60.0)
 	}
 
+	currentRate, _ := d.rateLimiter.Peek(limiterKey)
+
 	return map[string]interface{}{
-		"total_keystrokes":  d.keystrokeCount,
-		"session_duration":  duration,
-		"average_wpm":       wpm,
-		"current_rate":      d.rateLimiter.GetCurrentRate(),
-		"event_summary":     d.logger.GetSummary(),
-		"critical_events":   len(d.logger.GetEventsBySeverity(SeverityCritical)),
-		"warning_events":    len(d.logger.GetEventsBySeverity(SeverityWarning)),
-		"info_events":       len(d.logger.GetEventsBySeverity(SeverityInfo)),
+		"total_keystrokes": d.keystrokeCount,
+		"session_duration": duration,
+		"average_wpm":      wpm,
+		"current_rate":     currentRate,
+		"rate_limit_stats": d.rateLimiter.Snapshot()[limiterKey],
+		"event_summary":    d.logger.GetSummary(),
+		"critical_events":  len(d.logger.GetEventsBySeverity(SeverityCritical)),
+		"warning_events":   len(d.logger.GetEventsBySeverity(SeverityWarning)),
+		"info_events":      len(d.logger.GetEventsBySeverity(SeverityInfo)),
+		"cadence":          d.cadence.snapshot(),
 	}
 }
 
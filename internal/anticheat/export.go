@@ -0,0 +1,282 @@
+package anticheat
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// marshalReportJSON is the canonical JSON encoding of an AnalysisReport:
+// the bytes written to analysis.json and the bytes ReportChain hashes and
+// signs are always exactly this, so verification never has to worry about
+// re-encoding producing a different byte sequence.
+func marshalReportJSON(report *AnalysisReport) ([]byte, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return data, nil
+}
+
+// ReportExporter renders an AnalysisReport to a specific file format
+// inside sessionDir, returning the path it wrote. Multiple exporters can
+// be run over the same report - e.g. JSON for machine consumption and
+// HTML for a human reviewer - without re-deriving any of the analysis.
+type ReportExporter interface {
+	Export(report *AnalysisReport, sessionDir string) (string, error)
+}
+
+// JSONExporter writes the report as indented JSON, matching the original
+// SaveReport behavior. This is the canonical machine-readable form - the
+// one ReportChain hashes and signs.
+type JSONExporter struct{}
+
+// Export writes analysis.json with restricted (owner read-only)
+// permissions, since the file holds the final anti-cheat verdict.
+func (JSONExporter) Export(report *AnalysisReport, sessionDir string) (string, error) {
+	return writeReportFile(report, sessionDir, "analysis.json", 0400, marshalReportJSON)
+}
+
+// CSVExporter dumps the anti-cheat events as a flat CSV for spreadsheet
+// triage, one row per event.
+type CSVExporter struct{}
+
+func (CSVExporter) Export(report *AnalysisReport, sessionDir string) (string, error) {
+	path := fmt.Sprintf("%s/analysis_events.csv", sessionDir)
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0400)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write([]string{"timestamp", "severity", "type", "description"}); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, event := range report.AntiCheatEvents {
+		row := []string{
+			event.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
+			string(event.Severity),
+			event.Type,
+			event.Description,
+		}
+		if err := w.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return path, nil
+}
+
+// junitTestsuite/junitTestcase are a minimal subset of the JUnit XML
+// schema - enough for interview platforms and CI systems that already
+// know how to ingest test results to surface anti-cheat flags the same
+// way they surface failing tests.
+type junitTestsuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnitExporter renders the report's flags as a JUnit-style testsuite:
+// one passing "No anomalies detected" case, or one failing case per flag.
+type JUnitExporter struct{}
+
+func (JUnitExporter) Export(report *AnalysisReport, sessionDir string) (string, error) {
+	suite := junitTestsuite{Name: fmt.Sprintf("anticheat.%s", report.SessionID)}
+
+	for _, flag := range report.Flags {
+		tc := junitTestcase{Name: flag}
+		if flag != "No anomalies detected" {
+			tc.Failure = &junitFailure{Message: flag}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+
+	path := fmt.Sprintf("%s/analysis.xml", sessionDir)
+	if err := os.WriteFile(path, data, 0400); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// HTMLExporter renders a standalone HTML report with inline SVG plots of
+// WPM-over-time and the flight-time/interval histogram, for a human
+// reviewer who doesn't want to read raw JSON.
+type HTMLExporter struct{}
+
+const (
+	svgWidth  = 640
+	svgHeight = 160
+)
+
+func (HTMLExporter) Export(report *AnalysisReport, sessionDir string) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\">")
+	fmt.Fprintf(&b, "<title>Anti-cheat report: %s</title></head><body>\n", html.EscapeString(report.SessionID))
+	fmt.Fprintf(&b, "<h1>Session %s (%s)</h1>\n", html.EscapeString(report.SessionID), html.EscapeString(report.CandidateName))
+	fmt.Fprintf(&b, "<p><b>Verdict:</b> %s (confidence %.2f)</p>\n", html.EscapeString(report.Verdict), report.Confidence)
+
+	b.WriteString("<h2>Flags</h2>\n<ul>\n")
+	for _, flag := range report.Flags {
+		fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(flag))
+	}
+	b.WriteString("</ul>\n")
+
+	b.WriteString("<h2>WPM over time</h2>\n")
+	b.WriteString(wpmOverTimeSVG(report.TypingStats.TypingIntervals))
+
+	b.WriteString("<h2>Interval histogram</h2>\n")
+	b.WriteString(intervalHistogramSVG(report.TypingStats.TypingIntervals))
+
+	b.WriteString("</body></html>\n")
+
+	path := fmt.Sprintf("%s/analysis.html", sessionDir)
+	if err := os.WriteFile(path, []byte(b.String()), 0400); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// wpmOverTimeSVG renders each interval (converted to an instantaneous WPM
+// figure) as a polyline, so a reviewer can spot a sudden speed-up at a
+// glance instead of reading through TypingIntervals.
+func wpmOverTimeSVG(intervals []float64) string {
+	if len(intervals) == 0 {
+		return "<p>No keystroke timing data.</p>\n"
+	}
+
+	points := make([]float64, len(intervals))
+	maxWPM := 0.0
+	for i, interval := range intervals {
+		wpm := 12.0 / interval
+		points[i] = wpm
+		if wpm > maxWPM {
+			maxWPM = wpm
+		}
+	}
+	if maxWPM == 0 {
+		maxWPM = 1
+	}
+
+	var coords strings.Builder
+	for i, wpm := range points {
+		x := float64(i) / float64(len(points)-1) * svgWidth
+		if len(points) == 1 {
+			x = 0
+		}
+		y := svgHeight - (wpm/maxWPM)*svgHeight
+		fmt.Fprintf(&coords, "%s,%s ", strconv.FormatFloat(x, 'f', 1, 64), strconv.FormatFloat(y, 'f', 1, 64))
+	}
+
+	return fmt.Sprintf(
+		"<svg width=\"%d\" height=\"%d\" xmlns=\"http://www.w3.org/2000/svg\">"+
+			"<polyline points=\"%s\" fill=\"none\" stroke=\"steelblue\" stroke-width=\"2\"/></svg>\n",
+		svgWidth, svgHeight, strings.TrimSpace(coords.String()))
+}
+
+const histogramBuckets = 20
+
+// intervalHistogramSVG buckets the interval distribution and renders it
+// as a simple bar chart.
+func intervalHistogramSVG(intervals []float64) string {
+	if len(intervals) == 0 {
+		return "<p>No keystroke timing data.</p>\n"
+	}
+
+	minV, maxV := intervals[0], intervals[0]
+	for _, v := range intervals {
+		if v < minV {
+			minV = v
+		}
+		if v > maxV {
+			maxV = v
+		}
+	}
+	if maxV == minV {
+		maxV = minV + 1
+	}
+
+	buckets := make([]int, histogramBuckets)
+	for _, v := range intervals {
+		idx := int((v - minV) / (maxV - minV) * float64(histogramBuckets))
+		if idx >= histogramBuckets {
+			idx = histogramBuckets - 1
+		}
+		buckets[idx]++
+	}
+
+	maxCount := 0
+	for _, c := range buckets {
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+	if maxCount == 0 {
+		maxCount = 1
+	}
+
+	barWidth := float64(svgWidth) / float64(histogramBuckets)
+
+	var bars strings.Builder
+	for i, c := range buckets {
+		barHeight := float64(c) / float64(maxCount) * svgHeight
+		x := float64(i) * barWidth
+		y := svgHeight - barHeight
+		fmt.Fprintf(&bars, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"darkorange\"/>",
+			x, y, barWidth-1, barHeight)
+	}
+
+	return fmt.Sprintf(
+		"<svg width=\"%d\" height=\"%d\" xmlns=\"http://www.w3.org/2000/svg\">%s</svg>\n",
+		svgWidth, svgHeight, bars.String())
+}
+
+// writeReportFile is JSONExporter's marshal-then-write path. ReportChain
+// hashes the output of the same marshalReportJSON, so the canonical bytes
+// hashed by the chain are exactly the bytes written to analysis.json.
+func writeReportFile(report *AnalysisReport, sessionDir, filename string, perm os.FileMode, marshal func(*AnalysisReport) ([]byte, error)) (string, error) {
+	data, err := marshal(report)
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/%s", sessionDir, filename)
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,267 @@
+package anticheat
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// VerdictUpdate is one incremental verdict emitted by LiveAnalyzer while a
+// session is still running. It mirrors the verdict/confidence/flags shape
+// of the batch AnalysisReport so a proctor UI can render early and final
+// verdicts with the same widget.
+type VerdictUpdate struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Verdict        string    `json:"verdict"`
+	Confidence     float64   `json:"confidence_score"`
+	SuspicionScore float64   `json:"suspicion_score"`
+	Flags          []string  `json:"flags"`
+	Trigger        string    `json:"trigger"`
+}
+
+// LiveThresholds configures when LiveAnalyzer decides an accumulated change
+// is worth pushing to the update channel.
+type LiveThresholds struct {
+	// ScoreStep is the minimum increase in suspicion score, since the last
+	// emitted update, before another update is emitted on its own.
+	ScoreStep float64
+
+	// ReservoirSize bounds the number of typing intervals retained for
+	// percentile estimates (see LiveAnalyzer's reservoir sample).
+	ReservoirSize int
+}
+
+// DefaultLiveThresholds returns the thresholds used when a Detector builds
+// its own LiveAnalyzer.
+func DefaultLiveThresholds() LiveThresholds {
+	return LiveThresholds{ScoreStep: 10.0, ReservoirSize: 200}
+}
+
+// welford is Welford's online algorithm for mean and variance, letting
+// LiveAnalyzer track interval variance in O(1) memory instead of
+// retaining every interval observed over a long session.
+type welford struct {
+	count int
+	mean  float64
+	m2    float64
+}
+
+func (w *welford) add(x float64) {
+	w.count++
+	delta := x - w.mean
+	w.mean += delta / float64(w.count)
+	w.m2 += delta * (x - w.mean)
+}
+
+func (w *welford) variance() float64 {
+	if w.count < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.count-1)
+}
+
+// LiveAnalyzer mirrors AnalyzeSession/generateVerdict but is fed keystrokes
+// and anti-cheat events incrementally as a session runs, rather than
+// re-reading keystrokes.log/events.log once the session is over. Memory is
+// O(1) in session length: an EWMA for WPM, a reservoir sample of the
+// interval distribution for percentiles, and a Welford accumulator for
+// variance, in place of the batch analyzer's full TypingIntervals slice.
+type LiveAnalyzer struct {
+	thresholds LiveThresholds
+	updates    chan VerdictUpdate
+
+	mu              sync.Mutex
+	startTime       time.Time
+	lastKeystroke   time.Time
+	keystrokeCount  int
+	ewmaWPM         float64
+	interval        welford
+	reservoir       []float64
+	flags           []string
+	suspicionScore  float64
+	lastEmittedStep float64
+	events          []Event
+}
+
+const ewmaAlpha = 0.2
+
+// NewLiveAnalyzer creates a LiveAnalyzer. The returned update channel is
+// buffered but unbounded consumers aren't guaranteed - see Updates.
+func NewLiveAnalyzer(thresholds LiveThresholds) *LiveAnalyzer {
+	if thresholds.ScoreStep <= 0 {
+		thresholds.ScoreStep = 10.0
+	}
+	if thresholds.ReservoirSize <= 0 {
+		thresholds.ReservoirSize = 200
+	}
+
+	return &LiveAnalyzer{
+		thresholds: thresholds,
+		updates:    make(chan VerdictUpdate, 16),
+		startTime:  time.Now(),
+	}
+}
+
+// Updates returns the channel of incremental verdicts. If nobody is
+// draining it, LiveAnalyzer drops updates rather than block the keystroke
+// path - a proctor UI that connects late simply starts from the next
+// threshold crossing, and can call Freeze for the full-session picture.
+func (a *LiveAnalyzer) Updates() <-chan VerdictUpdate {
+	return a.updates
+}
+
+// RecordKeystroke folds a chunk of PTY input into the rolling WPM and
+// interval estimators.
+func (a *LiveAnalyzer) RecordKeystroke(data []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	a.keystrokeCount += len(data)
+
+	if !a.lastKeystroke.IsZero() {
+		interval := now.Sub(a.lastKeystroke).Seconds()
+		if interval > 0 && interval < 10 { // ignore long pauses, same bound as the batch analyzer
+			a.interval.add(interval)
+			a.sampleReservoir(interval)
+
+			wpm := 12.0 / interval // 12 = 60s/min / 5 chars/word
+			if a.ewmaWPM == 0 {
+				a.ewmaWPM = wpm
+			} else {
+				a.ewmaWPM = ewmaAlpha*wpm + (1-ewmaAlpha)*a.ewmaWPM
+			}
+		}
+	}
+	a.lastKeystroke = now
+
+	if a.ewmaWPM > 120 {
+		a.bumpScore(20.0, fmt.Sprintf("Unusually high WPM: %.1f", a.ewmaWPM), "wpm")
+	}
+}
+
+// sampleReservoir maintains a fixed-size uniform random sample of typing
+// intervals (reservoir sampling), so percentile estimates stay cheap
+// without keeping every interval seen over a long session.
+func (a *LiveAnalyzer) sampleReservoir(interval float64) {
+	if len(a.reservoir) < a.thresholds.ReservoirSize {
+		a.reservoir = append(a.reservoir, interval)
+		return
+	}
+	if j := rand.Intn(a.interval.count); j < a.thresholds.ReservoirSize {
+		a.reservoir[j] = interval
+	}
+}
+
+// RecordEvent folds an anti-cheat event (rate limit violation, paste
+// attempt, client-reported focus loss, ...) into the running suspicion
+// score. A critical event always forces an update, matching the weight
+// generateVerdict gives paste attempts.
+func (a *LiveAnalyzer) RecordEvent(event Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.events = append(a.events, event)
+
+	switch event.Severity {
+	case SeverityCritical:
+		a.suspicionScore += 30.0
+		a.flags = append(a.flags, fmt.Sprintf("Critical event: %s", event.Description))
+		a.lastEmittedStep = a.suspicionScore
+		a.emit("critical_event")
+	case SeverityWarning:
+		a.bumpScore(10.0, fmt.Sprintf("Warning event: %s", event.Description), "warning_event")
+	}
+}
+
+// bumpScore adds to the suspicion score and flag list, then emits an
+// update if the accumulated increase has crossed ScoreStep.
+func (a *LiveAnalyzer) bumpScore(amount float64, flag, trigger string) {
+	a.suspicionScore += amount
+	a.flags = append(a.flags, flag)
+
+	if a.suspicionScore-a.lastEmittedStep < a.thresholds.ScoreStep {
+		return
+	}
+	a.lastEmittedStep = a.suspicionScore
+	a.emit(trigger)
+}
+
+// emit pushes the current verdict onto the update channel. Caller must
+// hold a.mu.
+func (a *LiveAnalyzer) emit(trigger string) {
+	verdict, confidence := verdictFromScore(a.suspicionScore)
+
+	update := VerdictUpdate{
+		Timestamp:      time.Now(),
+		Verdict:        verdict,
+		Confidence:     confidence,
+		SuspicionScore: a.suspicionScore,
+		Flags:          append([]string(nil), a.flags...),
+		Trigger:        trigger,
+	}
+
+	select {
+	case a.updates <- update:
+	default:
+		// Slow or absent consumer: drop rather than block the keystroke path.
+	}
+}
+
+// Freeze produces a full AnalysisReport from the estimators accumulated so
+// far, in the same shape AnalyzeSession would produce from the finished
+// keystrokes.log/events.log. It can be called at any point in a running
+// session, not just at the end.
+func (a *LiveAnalyzer) Freeze() *AnalysisReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := TypingStats{
+		TotalKeystrokes: a.keystrokeCount,
+		SessionDuration: time.Since(a.startTime).Seconds(),
+		AverageWPM:      a.ewmaWPM,
+		MedianWPM:       a.percentileWPM(0.5),
+		MaxWPM:          a.percentileWPM(0.0), // shortest interval = fastest typing
+		MinWPM:          a.percentileWPM(1.0),
+		WPMStdDev:       math.Sqrt(a.interval.variance()) * 12.0,
+	}
+
+	verdict, confidence := verdictFromScore(a.suspicionScore)
+	flags := append([]string(nil), a.flags...)
+	if len(flags) == 0 {
+		flags = append(flags, "No anomalies detected")
+	}
+
+	events := append([]Event(nil), a.events...)
+
+	return &AnalysisReport{
+		AnalysisTimestamp: time.Now(),
+		TypingStats:       stats,
+		AntiCheatEvents:   events,
+		EventSummary:      summarizeEvents(events),
+		Verdict:           verdict,
+		Confidence:        confidence,
+		Flags:             flags,
+		Recommendations:   generateRecommendations(events, &stats),
+	}
+}
+
+// percentileWPM converts the p-th percentile (0-1) interval in the
+// reservoir sample back to a WPM figure.
+func (a *LiveAnalyzer) percentileWPM(p float64) float64 {
+	if len(a.reservoir) == 0 {
+		return a.ewmaWPM
+	}
+
+	sorted := append([]float64(nil), a.reservoir...)
+	sort.Float64s(sorted)
+	idx := int(p * float64(len(sorted)-1))
+	interval := sorted[idx]
+	if interval <= 0 {
+		return a.ewmaWPM
+	}
+	return 12.0 / interval
+}
@@ -0,0 +1,191 @@
+package anticheat
+
+import "time"
+
+// TimingConfig configures TimingAnalyzer's ring-buffer keystroke-timing
+// heuristics. Rolling chars-per-second rate limiting already lives in
+// security.Limiter (see Detector.CheckInput), so TimingAnalyzer instead
+// targets the two signals that need raw per-keystroke timestamps rather
+// than a running total: a burst whose interval variance is too low to
+// look typed, and an interval distribution split between a short and a
+// long cluster (type, pause, type, rather than one steady cadence).
+type TimingConfig struct {
+	RingSize int // number of recent keystrokes retained for these checks
+
+	PasteMinChars    int           // a burst must span at least this many chars
+	PasteWindow      time.Duration // ...arriving within this long a window
+	PasteMaxStdDevMs float64       // ...with interval stddev below this to look pasted
+
+	BimodalMinSamples    int     // minimum intervals in the ring before this check runs
+	BimodalShortMs       float64 // intervals at/below this fall in the short cluster
+	BimodalLongMs        float64 // intervals at/above this fall in the long cluster
+	BimodalMinClusterPct float64 // each cluster must hold at least this fraction of samples
+}
+
+// DefaultTimingConfig returns the thresholds used when config.Config
+// doesn't override them.
+func DefaultTimingConfig() TimingConfig {
+	return TimingConfig{
+		RingSize:             64,
+		PasteMinChars:        10,
+		PasteWindow:          150 * time.Millisecond,
+		PasteMaxStdDevMs:     8,
+		BimodalMinSamples:    20,
+		BimodalShortMs:       20,
+		BimodalLongMs:        400,
+		BimodalMinClusterPct: 0.15,
+	}
+}
+
+// timingEvent is one keystroke byte and the monotonic-clock timestamp
+// (time.Now() carries a monotonic reading) it arrived at.
+type timingEvent struct {
+	ts time.Time
+	b  byte
+}
+
+// TimingFindings reports what, if anything, TimingAnalyzer.Record found
+// in the current keystroke window.
+type TimingFindings struct {
+	PasteSuspected bool
+	BurstSize      int
+	BurstStdDevMs  float64
+
+	BimodalAnomaly     bool
+	ShortClusterMeanMs float64
+	LongClusterMeanMs  float64
+}
+
+// TimingAnalyzer maintains a fixed-size ring buffer of the most recent
+// keystrokes and evaluates it on every Record call. Unlike cadenceTracker
+// it keeps raw timestamps rather than a running interval window, since
+// the paste-burst check needs the actual span of its trailing window,
+// not just the mean interval within it.
+type TimingAnalyzer struct {
+	cfg TimingConfig
+
+	ring  []timingEvent
+	head  int
+	count int
+}
+
+func newTimingAnalyzer(cfg TimingConfig) *TimingAnalyzer {
+	size := cfg.RingSize
+	if size < 2 {
+		size = 2
+	}
+	return &TimingAnalyzer{cfg: cfg, ring: make([]timingEvent, size)}
+}
+
+func (t *TimingAnalyzer) push(ts time.Time, b byte) {
+	t.ring[t.head] = timingEvent{ts: ts, b: b}
+	t.head = (t.head + 1) % len(t.ring)
+	if t.count < len(t.ring) {
+		t.count++
+	}
+}
+
+// events returns the retained events in chronological order, oldest first.
+func (t *TimingAnalyzer) events() []timingEvent {
+	out := make([]timingEvent, t.count)
+	start := (t.head - t.count + len(t.ring)) % len(t.ring)
+	for i := 0; i < t.count; i++ {
+		out[i] = t.ring[(start+i)%len(t.ring)]
+	}
+	return out
+}
+
+// intervalsMs converts a chronological event slice into its consecutive
+// inter-keystroke intervals, in milliseconds.
+func intervalsMs(events []timingEvent) []float64 {
+	if len(events) < 2 {
+		return nil
+	}
+	out := make([]float64, 0, len(events)-1)
+	for i := 1; i < len(events); i++ {
+		out = append(out, float64(events[i].ts.Sub(events[i-1].ts).Microseconds())/1000.0)
+	}
+	return out
+}
+
+// Record folds data's bytes into the ring buffer and evaluates the
+// updated window against both heuristics.
+func (t *TimingAnalyzer) Record(data []byte) TimingFindings {
+	now := time.Now()
+	for _, b := range data {
+		t.push(now, b)
+	}
+
+	var findings TimingFindings
+
+	events := t.events()
+	if suspected, size, stddev := t.checkPasteBurst(events); suspected {
+		findings.PasteSuspected = true
+		findings.BurstSize = size
+		findings.BurstStdDevMs = stddev
+	}
+
+	if bimodal, shortMean, longMean := t.checkBimodal(intervalsMs(events)); bimodal {
+		findings.BimodalAnomaly = true
+		findings.ShortClusterMeanMs = shortMean
+		findings.LongClusterMeanMs = longMean
+	}
+
+	return findings
+}
+
+// checkPasteBurst looks at the trailing PasteMinChars keystrokes: if they
+// all arrived within PasteWindow and their interval stddev is below
+// PasteMaxStdDevMs, the burst looks pasted rather than typed (a human
+// typing that fast would show more jitter between keystrokes).
+func (t *TimingAnalyzer) checkPasteBurst(events []timingEvent) (suspected bool, burstSize int, stddevMs float64) {
+	n := t.cfg.PasteMinChars
+	if len(events) < n {
+		return false, 0, 0
+	}
+
+	tail := events[len(events)-n:]
+	if tail[len(tail)-1].ts.Sub(tail[0].ts) > t.cfg.PasteWindow {
+		return false, 0, 0
+	}
+
+	_, stddev := meanStdDev(intervalsMs(tail))
+	if stddev >= t.cfg.PasteMaxStdDevMs {
+		return false, 0, 0
+	}
+
+	return true, n, stddev
+}
+
+// checkBimodal splits the current interval window into a short cluster
+// (<= BimodalShortMs) and a long cluster (>= BimodalLongMs), ignoring
+// whatever falls between them, and fires once both clusters hold a
+// meaningful share of the window - very short intervals mixed with long
+// pauses, rather than one steady cadence.
+func (t *TimingAnalyzer) checkBimodal(intervals []float64) (bimodal bool, shortMeanMs, longMeanMs float64) {
+	if len(intervals) < t.cfg.BimodalMinSamples {
+		return false, 0, 0
+	}
+
+	var shortVals, longVals []float64
+	for _, v := range intervals {
+		switch {
+		case v <= t.cfg.BimodalShortMs:
+			shortVals = append(shortVals, v)
+		case v >= t.cfg.BimodalLongMs:
+			longVals = append(longVals, v)
+		}
+	}
+
+	total := float64(len(intervals))
+	if float64(len(shortVals))/total < t.cfg.BimodalMinClusterPct {
+		return false, 0, 0
+	}
+	if float64(len(longVals))/total < t.cfg.BimodalMinClusterPct {
+		return false, 0, 0
+	}
+
+	shortMean, _ := meanStdDev(shortVals)
+	longMean, _ := meanStdDev(longVals)
+	return true, shortMean, longMean
+}
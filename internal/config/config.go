@@ -1,11 +1,13 @@
 package config
 
 import (
+	"compress/flate"
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,25 +16,99 @@ type Config struct {
 	// Server
 	Port int
 
+	// GRPCPort serves AnticheatService (internal/grpcapi) for external
+	// analytics pipelines, separately from the HTTP/WS port above.
+	GRPCPort int
+
+	// Transport is the network transport the HTTP/WS server listens on:
+	// "tcp" (default) or "kcp" (reliable UDP with FEC, for lossy links)
+	Transport string
+	KCPKey    string // shared passphrase used to derive the KCP block cipher key
+
 	// Session
 	CandidateName   string
 	SessionTimeout  time.Duration
 	ReconnectWindow time.Duration
 
+	// ScrollbackLines bounds the reconnect replay's VT scrollback ring,
+	// independent of the raw PTY output volume.
+	ScrollbackLines int
+
 	// Paths
 	OutputDir string
 	Shell     string
 
+	// Session artifact storage (selects session.SessionStore in main.go)
+	StorageBackend string // "local" (default) or "s3"
+	S3Bucket       string
+	S3Endpoint     string
+	S3Region       string
+
 	// Recording
 	FlushInterval time.Duration
 
+	// Live streaming sinks (terminal.RecorderSink): tee every recorded
+	// event somewhere beyond the local session directory as it happens,
+	// for ephemeral-container deployments or live review.
+	RemoteSinkURL        string // "" disables remote sinks
+	RemoteSinkType       string // "s3", "webhook", or "syslog"
+	RemoteSinkBufferSize int    // per-sink queue depth before events are dropped
+
+	// WebSocket keepalive (see web.KeepaliveConfig)
+	WSPingInterval   time.Duration
+	WSPongTimeout    time.Duration
+	WSMaxMessageSize int64
+
+	// WebSocket permessage-deflate compression (see web.CompressionConfig)
+	WSCompressionLevel   int // compress/flate level: -2..9, -1 = default
+	WSCompressionMinSize int // frames smaller than this skip compression
+
+	// WSAllowedOrigins is the web.OriginPolicy allowlist applied to /ws,
+	// /watch, and /events upgrades. Empty means allow any origin, which
+	// preserves the pre-allowlist behavior for local/dev use.
+	WSAllowedOrigins []string
+
+	// WSRequireReconnectAuth gates all three upgrade endpoints behind
+	// web.ReconnectTokenAuthenticator, so a caller must present the
+	// session's reconnect token to open a connection at all. Off by
+	// default: the initial operator connection has no token to present
+	// yet (only a reconnect does), so enabling this is a deployment
+	// choice, not a safe default.
+	WSRequireReconnectAuth bool
+
 	// Anti-cheat
-	InputRateLimit int // chars per second
+	InputRateLimit          int    // chars per second
+	AutoTerminateSuspicious bool   // end the session early if the live verdict reaches SUSPICIOUS
+	AnticheatSignKeyPath    string // path to a hex-encoded Ed25519 private key; empty disables signed report chaining
+
+	// Keystroke-dynamics thresholds (see anticheat.cadenceTracker)
+	CadenceWindowSize            int     // rolling number of inter-key intervals to judge cadence over
+	CadenceMinCV                 float64 // coefficient of variation below this looks mechanically regular
+	CadenceHistogramBucketMs     float64 // histogram bucket width, in ms
+	CadenceHistogramDominantRate float64 // fraction of samples in one bucket that flags robotic cadence
+	CadenceDigraphMinStdDevMs    float64 // below this, a repeated bigram's timing looks replayed
+	CadenceDigraphMinOccurrences int     // minimum repeats of a bigram before judging its stddev
+
+	// Ring-buffer keystroke-timing thresholds (see anticheat.TimingAnalyzer)
+	TimingRingSize             int           // recent keystrokes retained for these checks
+	TimingPasteMinChars        int           // a burst must span at least this many chars
+	TimingPasteWindow          time.Duration // ...arriving within this long a window
+	TimingPasteMaxStdDevMs     float64       // ...with interval stddev below this to look pasted
+	TimingBimodalMinSamples    int           // minimum intervals in the ring before this check runs
+	TimingBimodalShortMs       float64       // intervals at/below this fall in the short cluster
+	TimingBimodalLongMs        float64       // intervals at/above this fall in the long cluster
+	TimingBimodalMinClusterPct float64       // each cluster must hold at least this fraction of samples
 
 	// Security
 	NetworkIsolated bool
 	ShellUID        uint32 // Random UID for shell user (generated at startup)
 
+	// Namespace + cgroup isolation (beyond the always-on setuid isolation)
+	NamespaceIsolation bool
+	MemLimitMB         int
+	PidsMax            int
+	CPUQuota           float64
+
 	// Observability
 	EnableMetrics bool
 	LogLevel      string
@@ -49,19 +125,57 @@ func Load() (*Config, error) {
 	shellUID := generateShellUID()
 
 	cfg := &Config{
-		Port:            getEnvInt("PORT", 8080),
-		CandidateName:   getEnv("CANDIDATE_NAME", "anonymous"),
-		SessionTimeout:  time.Duration(getEnvInt("SESSION_TIMEOUT", 7200)) * time.Second,
-		ReconnectWindow: time.Duration(getEnvInt("RECONNECT_WINDOW", 300)) * time.Second,
-		OutputDir:       getEnv("OUTPUT_DIR", "./sessions"),
-		Shell:           getEnv("SHELL", "/bin/bash"),
-		FlushInterval:   time.Duration(getEnvInt("FLUSH_INTERVAL", 10)) * time.Second,
-		InputRateLimit:  getEnvInt("INPUT_RATE_LIMIT", 30),
-		NetworkIsolated: getEnvBool("NETWORK_ISOLATED", true),
-		ShellUID:        shellUID,
-		EnableMetrics:   getEnvBool("ENABLE_METRICS", true),
-		LogLevel:        getEnv("LOG_LEVEL", "info"),
-		MOTD:            getEnv("MOTD", defaultMOTD()),
+		Port:                         getEnvInt("PORT", 8080),
+		GRPCPort:                     getEnvInt("GRPC_PORT", 9090),
+		Transport:                    getEnv("TRANSPORT", "tcp"),
+		KCPKey:                       getEnv("KCP_KEY", "echobox-default-key"),
+		CandidateName:                getEnv("CANDIDATE_NAME", "anonymous"),
+		SessionTimeout:               time.Duration(getEnvInt("SESSION_TIMEOUT", 7200)) * time.Second,
+		ReconnectWindow:              time.Duration(getEnvInt("RECONNECT_WINDOW", 300)) * time.Second,
+		ScrollbackLines:              getEnvInt("SCROLLBACK_LINES", 1000),
+		OutputDir:                    getEnv("OUTPUT_DIR", "./sessions"),
+		Shell:                        getEnv("SHELL", "/bin/bash"),
+		StorageBackend:               getEnv("STORAGE_BACKEND", "local"),
+		S3Bucket:                     getEnv("S3_BUCKET", ""),
+		S3Endpoint:                   getEnv("S3_ENDPOINT", ""),
+		S3Region:                     getEnv("S3_REGION", "us-east-1"),
+		FlushInterval:                time.Duration(getEnvInt("FLUSH_INTERVAL", 10)) * time.Second,
+		RemoteSinkURL:                getEnv("REMOTE_SINK_URL", ""),
+		RemoteSinkType:               getEnv("REMOTE_SINK_TYPE", ""),
+		RemoteSinkBufferSize:         getEnvInt("REMOTE_SINK_BUFFER_SIZE", 256),
+		WSPingInterval:               time.Duration(getEnvInt("WS_PING_INTERVAL_SECONDS", 30)) * time.Second,
+		WSPongTimeout:                time.Duration(getEnvInt("WS_PONG_TIMEOUT_SECONDS", 60)) * time.Second,
+		WSMaxMessageSize:             getEnvInt64("WS_MAX_MESSAGE_SIZE_BYTES", 1<<20),
+		WSCompressionLevel:           getEnvInt("WS_COMPRESSION_LEVEL", flate.DefaultCompression),
+		WSCompressionMinSize:         getEnvInt("WS_COMPRESSION_MIN_SIZE_BYTES", 256),
+		WSAllowedOrigins:             getEnvStringSlice("WS_ALLOWED_ORIGINS", nil),
+		WSRequireReconnectAuth:       getEnvBool("WS_REQUIRE_RECONNECT_AUTH", false),
+		InputRateLimit:               getEnvInt("INPUT_RATE_LIMIT", 30),
+		AutoTerminateSuspicious:      getEnvBool("AUTO_TERMINATE_SUSPICIOUS", false),
+		AnticheatSignKeyPath:         getEnv("ANTICHEAT_SIGN_KEY_PATH", ""),
+		CadenceWindowSize:            getEnvInt("CADENCE_WINDOW_SIZE", 200),
+		CadenceMinCV:                 getEnvFloat("CADENCE_MIN_CV", 0.35),
+		CadenceHistogramBucketMs:     getEnvFloat("CADENCE_HISTOGRAM_BUCKET_MS", 10),
+		CadenceHistogramDominantRate: getEnvFloat("CADENCE_HISTOGRAM_DOMINANT_RATE", 0.70),
+		CadenceDigraphMinStdDevMs:    getEnvFloat("CADENCE_DIGRAPH_MIN_STDDEV_MS", 15),
+		CadenceDigraphMinOccurrences: getEnvInt("CADENCE_DIGRAPH_MIN_OCCURRENCES", 3),
+		TimingRingSize:               getEnvInt("TIMING_RING_SIZE", 64),
+		TimingPasteMinChars:          getEnvInt("TIMING_PASTE_MIN_CHARS", 10),
+		TimingPasteWindow:            time.Duration(getEnvInt("TIMING_PASTE_WINDOW_MS", 150)) * time.Millisecond,
+		TimingPasteMaxStdDevMs:       getEnvFloat("TIMING_PASTE_MAX_STDDEV_MS", 8),
+		TimingBimodalMinSamples:      getEnvInt("TIMING_BIMODAL_MIN_SAMPLES", 20),
+		TimingBimodalShortMs:         getEnvFloat("TIMING_BIMODAL_SHORT_MS", 20),
+		TimingBimodalLongMs:          getEnvFloat("TIMING_BIMODAL_LONG_MS", 400),
+		TimingBimodalMinClusterPct:   getEnvFloat("TIMING_BIMODAL_MIN_CLUSTER_PCT", 0.15),
+		NetworkIsolated:              getEnvBool("NETWORK_ISOLATED", true),
+		ShellUID:                     shellUID,
+		NamespaceIsolation:           getEnvBool("NAMESPACE_ISOLATION", false),
+		MemLimitMB:                   getEnvInt("MEM_LIMIT_MB", 512),
+		PidsMax:                      getEnvInt("PIDS_MAX", 256),
+		CPUQuota:                     getEnvFloat("CPU_QUOTA", 1.0),
+		EnableMetrics:                getEnvBool("ENABLE_METRICS", true),
+		LogLevel:                     getEnv("LOG_LEVEL", "info"),
+		MOTD:                         getEnv("MOTD", defaultMOTD()),
 	}
 
 	// Validation
@@ -69,6 +183,10 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("PORT must be between 1 and 65535, got %d", cfg.Port)
 	}
 
+	if cfg.GRPCPort < 1 || cfg.GRPCPort > 65535 {
+		return nil, fmt.Errorf("GRPC_PORT must be between 1 and 65535, got %d", cfg.GRPCPort)
+	}
+
 	if cfg.CandidateName == "" {
 		return nil, fmt.Errorf("CANDIDATE_NAME cannot be empty")
 	}
@@ -81,6 +199,42 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("OUTPUT_DIR cannot be empty")
 	}
 
+	if cfg.Transport != "tcp" && cfg.Transport != "kcp" {
+		return nil, fmt.Errorf("TRANSPORT must be 'tcp' or 'kcp', got %q", cfg.Transport)
+	}
+
+	if cfg.StorageBackend != "local" && cfg.StorageBackend != "s3" {
+		return nil, fmt.Errorf("STORAGE_BACKEND must be 'local' or 's3', got %q", cfg.StorageBackend)
+	}
+
+	if cfg.StorageBackend == "s3" && cfg.S3Bucket == "" {
+		return nil, fmt.Errorf("S3_BUCKET is required when STORAGE_BACKEND=s3")
+	}
+
+	if cfg.WSPingInterval <= 0 {
+		return nil, fmt.Errorf("WS_PING_INTERVAL_SECONDS must be positive")
+	}
+
+	if cfg.WSPongTimeout <= cfg.WSPingInterval {
+		return nil, fmt.Errorf("WS_PONG_TIMEOUT_SECONDS must be greater than WS_PING_INTERVAL_SECONDS")
+	}
+
+	if cfg.WSCompressionLevel < flate.HuffmanOnly || cfg.WSCompressionLevel > flate.BestCompression {
+		return nil, fmt.Errorf("WS_COMPRESSION_LEVEL must be between %d and %d, got %d", flate.HuffmanOnly, flate.BestCompression, cfg.WSCompressionLevel)
+	}
+
+	if cfg.WSCompressionMinSize < 0 {
+		return nil, fmt.Errorf("WS_COMPRESSION_MIN_SIZE_BYTES must be non-negative")
+	}
+
+	if cfg.RemoteSinkURL != "" {
+		switch cfg.RemoteSinkType {
+		case "s3", "webhook", "syslog":
+		default:
+			return nil, fmt.Errorf("REMOTE_SINK_TYPE must be 's3', 'webhook', or 'syslog', got %q", cfg.RemoteSinkType)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -101,6 +255,24 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolVal, err := strconv.ParseBool(value); err == nil {
@@ -110,6 +282,27 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvStringSlice splits a comma-separated env var into a slice,
+// trimming whitespace around each entry and dropping empty entries.
+// An unset or empty env var returns defaultValue unchanged.
+func getEnvStringSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
 func defaultMOTD() string {
 	return `
 ╔══════════════════════════════════════════════════════════════╗
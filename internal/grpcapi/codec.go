@@ -0,0 +1,34 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// jsonCodecName is registered as a gRPC content-subtype, so clients can
+// talk to AnticheatService with plain JSON-tagged structs (types.go)
+// instead of requiring a full protoc-gen-go toolchain on either end.
+const jsonCodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("grpcapi: unmarshal: %w", err)
+	}
+	return nil
+}
+
+func (jsonCodec) Name() string {
+	return jsonCodecName
+}
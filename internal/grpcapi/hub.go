@@ -0,0 +1,59 @@
+package grpcapi
+
+import (
+	"io"
+	"sync"
+)
+
+// chunkHub fans a stream of byte chunks out to any number of subscribers.
+// It mirrors the AddReader/AddWriter fan-out already used by terminal.PTY,
+// but backed by per-subscriber channels instead of a second io.Writer tap,
+// since gRPC streams need to block on "next chunk" rather than accept a
+// synchronous Write call.
+type chunkHub struct {
+	mu   sync.Mutex
+	subs map[chan []byte]struct{}
+}
+
+func newChunkHub() *chunkHub {
+	return &chunkHub{subs: make(map[chan []byte]struct{})}
+}
+
+// Write implements io.Writer so a chunkHub can be registered directly with
+// PTY.AddReader / PTY.AddWriter.
+func (h *chunkHub) Write(p []byte) (int, error) {
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		select {
+		case sub <- chunk:
+		default:
+			// Subscriber is behind; drop the chunk rather than block the
+			// PTY fan-out for every other consumer.
+		}
+	}
+	return len(p), nil
+}
+
+// Read satisfies io.Reader so chunkHub can be registered via
+// PTY.AddReader; the PTY never actually reads from a hub.
+func (h *chunkHub) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (h *chunkHub) subscribe() chan []byte {
+	ch := make(chan []byte, 64)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *chunkHub) unsubscribe(ch chan []byte) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+}
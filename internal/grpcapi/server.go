@@ -0,0 +1,175 @@
+// Package grpcapi exposes a session's PTY output, keystrokes, and
+// anti-cheat report over gRPC so external analytics pipelines and
+// ML-based cheat classifiers can consume them without forking echobox.
+//
+// There is no protoc-generated client/server stub here: echobox.proto
+// documents the contract, but the service is wired up by hand with a
+// grpc.ServiceDesc (the same shape protoc-gen-go-grpc would emit) and a
+// JSON codec (codec.go) so messages stay plain json-tagged structs.
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/akonyukov/echobox/internal/anticheat"
+	"github.com/akonyukov/echobox/internal/terminal"
+)
+
+// Server implements AnticheatService against a single running session.
+type Server struct {
+	sessionID  string
+	sessionDir string
+	output     *chunkHub
+	keystrokes *chunkHub
+}
+
+// NewServer taps the session's PTY for output and keystrokes, and records
+// where to find the session's anti-cheat report once one exists.
+func NewServer(sessionID, sessionDir string, pty *terminal.PTY) *Server {
+	s := &Server{
+		sessionID:  sessionID,
+		sessionDir: sessionDir,
+		output:     newChunkHub(),
+		keystrokes: newChunkHub(),
+	}
+	pty.AddReader(s.output)
+	pty.AddWriter(s.keystrokes)
+	return s
+}
+
+// StreamPTYOutput streams PTY output chunks as they're produced, until the
+// stream's context is done (e.g. the client disconnects). The subscription
+// is not closed when the session ends.
+func (s *Server) StreamPTYOutput(req *SessionRequest, stream grpc.ServerStream) error {
+	sub := s.output.subscribe()
+	defer s.output.unsubscribe(sub)
+
+	for {
+		select {
+		case chunk, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			frame := &Frame{TimestampMS: time.Now().UnixMilli(), Data: chunk}
+			if err := stream.SendMsg(frame); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// StreamKeystrokes streams candidate input chunks as they're written to
+// the PTY, until the stream's context is done (e.g. the client
+// disconnects). The subscription is not closed when the session ends.
+func (s *Server) StreamKeystrokes(req *SessionRequest, stream grpc.ServerStream) error {
+	sub := s.keystrokes.subscribe()
+	defer s.keystrokes.unsubscribe(sub)
+
+	for {
+		select {
+		case chunk, ok := <-sub:
+			if !ok {
+				return nil
+			}
+			keystroke := &Keystroke{TimestampMS: time.Now().UnixMilli(), Data: chunk}
+			if err := stream.SendMsg(keystroke); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// GetAnticheatReport runs (or re-runs) post-session analysis and returns a
+// trimmed-down view of the report. It is only meaningful once the session
+// has produced a keystrokes.log, i.e. after it has started.
+func (s *Server) GetAnticheatReport(ctx context.Context, req *SessionRequest) (*AnticheatReport, error) {
+	report, err := anticheat.AnalyzeSession(s.sessionDir)
+	if err != nil {
+		return nil, err
+	}
+	return &AnticheatReport{
+		Verdict:    report.Verdict,
+		Confidence: report.Confidence,
+		Flags:      report.Flags,
+	}, nil
+}
+
+func decodeSessionRequest(dec func(any) error) (*SessionRequest, error) {
+	req := new(SessionRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func _AnticheatService_StreamPTYOutput_Handler(srv any, stream grpc.ServerStream) error {
+	req, err := decodeStreamRequest(stream)
+	if err != nil {
+		return err
+	}
+	return srv.(*Server).StreamPTYOutput(req, stream)
+}
+
+func _AnticheatService_StreamKeystrokes_Handler(srv any, stream grpc.ServerStream) error {
+	req, err := decodeStreamRequest(stream)
+	if err != nil {
+		return err
+	}
+	return srv.(*Server).StreamKeystrokes(req, stream)
+}
+
+func decodeStreamRequest(stream grpc.ServerStream) (*SessionRequest, error) {
+	req := new(SessionRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func _AnticheatService_GetAnticheatReport_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req, err := decodeSessionRequest(dec)
+	if err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).GetAnticheatReport(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/echobox.AnticheatService/GetAnticheatReport"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).GetAnticheatReport(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// ServiceDesc is the hand-built equivalent of the protoc-gen-go-grpc
+// output for AnticheatService (see echobox.proto).
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "echobox.AnticheatService",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAnticheatReport",
+			Handler:    _AnticheatService_GetAnticheatReport_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPTYOutput",
+			Handler:       _AnticheatService_StreamPTYOutput_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamKeystrokes",
+			Handler:       _AnticheatService_StreamKeystrokes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/grpcapi/echobox.proto",
+}
@@ -0,0 +1,28 @@
+package grpcapi
+
+// SessionRequest identifies which session a streaming RPC should tap.
+// Echobox runs one session per process, so this is mostly forward
+// compatibility for a future multi-tenant daemon.
+type SessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// Frame is a single chunk of PTY output.
+type Frame struct {
+	TimestampMS int64  `json:"timestamp_ms"`
+	Data        []byte `json:"data"`
+}
+
+// Keystroke is a single chunk of candidate input.
+type Keystroke struct {
+	TimestampMS int64  `json:"timestamp_ms"`
+	Data        []byte `json:"data"`
+}
+
+// AnticheatReport mirrors the fields of anticheat.AnalysisReport that are
+// useful to an external consumer without pulling in the whole report.
+type AnticheatReport struct {
+	Verdict    string   `json:"verdict"`
+	Confidence float64  `json:"confidence"`
+	Flags      []string `json:"flags"`
+}
@@ -11,85 +11,196 @@ type InputEvent struct {
 	Length    int
 }
 
-// RateLimiter tracks input rate to detect paste attempts
-type RateLimiter struct {
-	MaxCharsPerSecond int
-	windowSize        time.Duration
-	events            []InputEvent
-	mu                sync.Mutex
+const (
+	defaultRingCapacity = 64
+	basePatience        = 500 * time.Millisecond
+	maxPatienceCap      = 30 * time.Second
+)
+
+// ringEvent is one InputEvent stored in a limiterWindow's ring buffer.
+type ringEvent struct {
+	ts     time.Time
+	length int
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(maxCharsPerSecond int) *RateLimiter {
-	return &RateLimiter{
-		MaxCharsPerSecond: maxCharsPerSecond,
-		windowSize:        time.Second,
-		events:            make([]InputEvent, 0),
-	}
+// limiterWindow is the sliding-window state tracked for a single key. The
+// ring buffer holds only events still inside the window; evictBefore
+// drops expired entries from the head as new ones are pushed at the
+// tail, so steady-state Check calls touch O(1) entries instead of
+// rebuilding a filtered slice every time.
+type limiterWindow struct {
+	buf        []ringEvent
+	head       int
+	count      int
+	totalChars int
+
+	hits          int
+	violations    int
+	patience      time.Duration
+	cooldownUntil time.Time
 }
 
-// CheckInput checks if input is within acceptable rate
-// Returns: allowed (bool), currentRate (int), violation (bool)
-func (r *RateLimiter) CheckInput(length int) (bool, int, bool) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+func newLimiterWindow() *limiterWindow {
+	return &limiterWindow{buf: make([]ringEvent, defaultRingCapacity)}
+}
 
-	now := time.Now()
-	event := InputEvent{
-		Timestamp: now,
-		Length:    length,
+func (w *limiterWindow) push(ts time.Time, length int) {
+	if w.count == len(w.buf) {
+		w.grow()
 	}
+	idx := (w.head + w.count) % len(w.buf)
+	w.buf[idx] = ringEvent{ts: ts, length: length}
+	w.count++
+	w.totalChars += length
+}
 
-	// Add current event
-	r.events = append(r.events, event)
+func (w *limiterWindow) evictBefore(cutoff time.Time) {
+	for w.count > 0 {
+		oldest := w.buf[w.head]
+		if oldest.ts.After(cutoff) {
+			break
+		}
+		w.totalChars -= oldest.length
+		w.head = (w.head + 1) % len(w.buf)
+		w.count--
+	}
+}
+
+func (w *limiterWindow) grow() {
+	newBuf := make([]ringEvent, len(w.buf)*2)
+	for i := 0; i < w.count; i++ {
+		newBuf[i] = w.buf[(w.head+i)%len(w.buf)]
+	}
+	w.buf = newBuf
+	w.head = 0
+}
 
-	// Remove events outside the window
-	cutoff := now.Add(-r.windowSize)
-	validEvents := make([]InputEvent, 0)
-	for _, e := range r.events {
-		if e.Timestamp.After(cutoff) {
-			validEvents = append(validEvents, e)
+// escalate doubles patience (starting from basePatience) on every
+// violation, capped at maxPatienceCap, and opens a cooldown window
+// during which Check reports the key as disallowed.
+func (w *limiterWindow) escalate(now time.Time, cap time.Duration) {
+	if w.patience == 0 {
+		w.patience = basePatience
+	} else {
+		w.patience *= 2
+		if w.patience > cap {
+			w.patience = cap
 		}
 	}
-	r.events = validEvents
+	w.cooldownUntil = now.Add(w.patience)
+}
 
-	// Calculate total characters in current window
-	totalChars := 0
-	for _, e := range r.events {
-		totalChars += e.Length
+// decay resets patience to baseline once a quiet period (as long as the
+// last cooldown itself) has passed with no further violations.
+func (w *limiterWindow) decay(now time.Time) {
+	if w.patience > 0 && now.Sub(w.cooldownUntil) > w.patience {
+		w.patience = 0
+		w.cooldownUntil = time.Time{}
 	}
+}
 
-	// Check if rate is exceeded
-	currentRate := totalChars
-	violation := currentRate > r.MaxCharsPerSecond
+// LimiterStats is a per-key snapshot of a Limiter's sliding-window state.
+type LimiterStats struct {
+	Hits       int
+	Violations int
+	Patience   time.Duration
+}
+
+// Limiter is a sliding-window rate limiter keyed by an arbitrary identity
+// (session ID, remote IP, candidate name), so a single Limiter can serve
+// many concurrent sessions without their counts cross-contaminating.
+type Limiter[K comparable] struct {
+	MaxCharsPerSecond int
 
-	// Allow input but report violation
-	return true, currentRate, violation
+	windowSize  time.Duration
+	maxPatience time.Duration
+
+	mu      sync.Mutex
+	windows map[K]*limiterWindow
+}
+
+// NewLimiter creates a new identity-scoped rate limiter.
+func NewLimiter[K comparable](maxCharsPerSecond int) *Limiter[K] {
+	return &Limiter[K]{
+		MaxCharsPerSecond: maxCharsPerSecond,
+		windowSize:        time.Second,
+		maxPatience:       maxPatienceCap,
+		windows:           make(map[K]*limiterWindow),
+	}
 }
 
-// GetCurrentRate returns the current input rate (chars/second)
-func (r *RateLimiter) GetCurrentRate() int {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// Check records an input event for key and reports whether it's allowed,
+// the key's current windowed rate, and whether this call violated the
+// rate limit. allowed is false while key is serving an escalated
+// cooldown from a prior violation, even if this particular call is
+// itself under the limit.
+func (l *Limiter[K]) Check(key K, length int) (allowed bool, currentRate int, violation bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = newLimiterWindow()
+		l.windows[key] = w
+	}
 
 	now := time.Now()
-	cutoff := now.Add(-r.windowSize)
+	w.evictBefore(now.Add(-l.windowSize))
+	w.push(now, length)
+	w.hits++
 
-	totalChars := 0
-	for _, e := range r.events {
-		if e.Timestamp.After(cutoff) {
-			totalChars += e.Length
-		}
+	currentRate = w.totalChars
+	violation = currentRate > l.MaxCharsPerSecond
+	allowed = w.cooldownUntil.IsZero() || !now.Before(w.cooldownUntil)
+
+	if violation {
+		w.violations++
+		w.escalate(now, l.maxPatience)
+		allowed = false
+	} else if !w.cooldownUntil.IsZero() {
+		w.decay(now)
 	}
 
-	return totalChars
+	return allowed, currentRate, violation
+}
+
+// Peek reports key's current windowed rate without recording an event,
+// for pre-flight checks that shouldn't themselves count toward the rate.
+func (l *Limiter[K]) Peek(key K) (rate int, wouldViolate bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok {
+		return 0, false
+	}
+
+	w.evictBefore(time.Now().Add(-l.windowSize))
+	return w.totalChars, w.totalChars > l.MaxCharsPerSecond
+}
+
+// Snapshot returns per-key statistics for exposure through a stats
+// endpoint: hits, violations, and the key's current escalated patience.
+func (l *Limiter[K]) Snapshot() map[K]LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make(map[K]LimiterStats, len(l.windows))
+	for key, w := range l.windows {
+		out[key] = LimiterStats{
+			Hits:       w.hits,
+			Violations: w.violations,
+			Patience:   w.patience,
+		}
+	}
+	return out
 }
 
-// Reset clears all tracked events
-func (r *RateLimiter) Reset() {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.events = make([]InputEvent, 0)
+// Reset clears all tracked state for key.
+func (l *Limiter[K]) Reset(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.windows, key)
 }
 
 // BurstDetector detects rapid bursts of input (potential paste)
@@ -0,0 +1,115 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLimiterKeysAreIndependent checks that two keys never share a
+// sliding window: driving one key past its limit must not affect the
+// other key's allowed/violation outcome.
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	l := NewLimiter[string](10)
+
+	if allowed, _, violation := l.Check("candidate-a", 100); allowed || !violation {
+		t.Fatalf("candidate-a: got allowed=%v violation=%v, want allowed=false violation=true (100 chars exceeds MaxCharsPerSecond=10)", allowed, violation)
+	}
+
+	if allowed, rate, violation := l.Check("candidate-b", 1); !allowed || violation {
+		t.Fatalf("candidate-b: got allowed=%v rate=%d violation=%v, want allowed=true violation=false (unaffected by candidate-a's burst)", allowed, rate, violation)
+	}
+}
+
+// TestLimiterEscalatesCooldownOnRepeatedViolation checks that Check
+// reports allowed=false immediately after a violation (the cooldown
+// escalation chunk1-2 added), that Peek agrees without itself counting
+// as a new event, and that the second violation doubles the patience.
+func TestLimiterEscalatesCooldownOnRepeatedViolation(t *testing.T) {
+	l := NewLimiter[string](10)
+
+	if _, _, violation := l.Check("k", 50); !violation {
+		t.Fatalf("first Check should violate a MaxCharsPerSecond=10 limit with length 50")
+	}
+
+	allowed, rate, _ := l.Check("k", 1)
+	if allowed {
+		t.Fatalf("Check during cooldown should report allowed=false, got true")
+	}
+	if rate == 0 {
+		t.Fatalf("currentRate should reflect the recorded event, got 0")
+	}
+
+	peekRate, wouldViolate := l.Peek("k")
+	if peekRate != rate {
+		t.Fatalf("Peek rate = %d, want %d to match the last Check", peekRate, rate)
+	}
+	if !wouldViolate {
+		t.Fatalf("Peek should report wouldViolate=true while still over MaxCharsPerSecond")
+	}
+
+	// Both Checks violated, so patience went basePatience -> 2*basePatience.
+	if patience := l.Snapshot()["k"].Patience; patience != 2*basePatience {
+		t.Fatalf("Patience after two violations = %v, want %v", patience, 2*basePatience)
+	}
+}
+
+// TestLimiterSnapshotReportsHitsAndViolations checks Snapshot's counters
+// after a mix of compliant and violating calls for the same key.
+func TestLimiterSnapshotReportsHitsAndViolations(t *testing.T) {
+	l := NewLimiter[string](10)
+
+	l.Check("k", 1)
+	l.Check("k", 1)
+	l.Check("k", 100)
+
+	stats := l.Snapshot()["k"]
+	if stats.Hits != 3 {
+		t.Fatalf("Hits = %d, want 3", stats.Hits)
+	}
+	if stats.Violations != 1 {
+		t.Fatalf("Violations = %d, want 1", stats.Violations)
+	}
+	if stats.Patience <= 0 {
+		t.Fatalf("Patience should be escalated above zero after a violation")
+	}
+}
+
+// TestLimiterResetClearsState checks that Reset drops a key's window
+// entirely, rather than just zeroing counters, so a subsequent Check
+// starts cold.
+func TestLimiterResetClearsState(t *testing.T) {
+	l := NewLimiter[string](10)
+
+	l.Check("k", 100)
+	l.Check("k", 1)
+
+	l.Reset("k")
+
+	if _, ok := l.Snapshot()["k"]; ok {
+		t.Fatalf("Snapshot should not contain key %q after Reset", "k")
+	}
+}
+
+// TestBurstDetectorDetectsRapidInput checks that a BurstDetector flags a
+// burst once accumulated chars within burstWindow exceed the threshold,
+// and that Reset clears the accumulated burst.
+func TestBurstDetectorDetectsRapidInput(t *testing.T) {
+	b := NewBurstDetector(10, time.Hour)
+
+	if isBurst, size := b.CheckBurst(5); isBurst {
+		t.Fatalf("first chunk (5 chars) should not be a burst, got size %d", size)
+	}
+
+	isBurst, size := b.CheckBurst(10)
+	if !isBurst {
+		t.Fatalf("cumulative 15 chars should exceed maxCharsInBurst=10")
+	}
+	if size != 15 {
+		t.Fatalf("burst size = %d, want 15", size)
+	}
+
+	b.Reset()
+	if isBurst, size := b.CheckBurst(5); isBurst {
+		t.Fatalf("after Reset, a fresh 5-char chunk should not be a burst, got size %d", size)
+	}
+}
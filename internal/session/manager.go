@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -9,22 +10,24 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
+	"github.com/akonyukov/echobox/internal/terminal"
 	"github.com/google/uuid"
 )
 
 // Session represents an interview session
 type Session struct {
-	ID           string    `json:"id"`
-	CandidateName string   `json:"candidate_name"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time,omitempty"`
-	Duration     float64   `json:"duration_seconds,omitempty"`
-	OutputDir    string    `json:"output_dir"`
-	Status       string    `json:"status"` // active, completed, error
-	FileHashes   map[string]string `json:"file_hashes,omitempty"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	ID            string                 `json:"id"`
+	CandidateName string                 `json:"candidate_name"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time,omitempty"`
+	Duration      float64                `json:"duration_seconds,omitempty"`
+	OutputDir     string                 `json:"output_dir"`
+	Status        string                 `json:"status"` // active, completed, error
+	FileHashes    map[string]string      `json:"file_hashes,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // Manager manages session lifecycle and recording
@@ -33,10 +36,11 @@ type Manager struct {
 	state      *SessionState
 	baseDir    string
 	sessionDir string
+	store      SessionStore
 }
 
 // NewManager creates a new session manager
-func NewManager(baseDir, candidateName string, reconnectWindow time.Duration) (*Manager, error) {
+func NewManager(baseDir, candidateName string, reconnectWindow time.Duration, scrollbackLines int) (*Manager, error) {
 	// Create base output directory if it doesn't exist
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create base directory: %w", err)
@@ -65,13 +69,14 @@ func NewManager(baseDir, candidateName string, reconnectWindow time.Duration) (*
 	}
 
 	// Create session state for reconnection support
-	state := NewSessionState(reconnectWindow)
+	state := NewSessionState(reconnectWindow, scrollbackLines)
 
 	m := &Manager{
 		session:    session,
 		state:      state,
 		baseDir:    baseDir,
 		sessionDir: sessionDir,
+		store:      NewLocalStore(baseDir),
 	}
 
 	// Store reconnect token in metadata
@@ -91,6 +96,12 @@ func (m *Manager) GetState() *SessionState {
 	return m.state
 }
 
+// SetStore overrides the SessionStore used for finalized artifact uploads.
+// By default a Manager uses a LocalStore rooted at its base directory.
+func (m *Manager) SetStore(store SessionStore) {
+	m.store = store
+}
+
 // GetSession returns the current session
 func (m *Manager) GetSession() *Session {
 	return m.session
@@ -101,6 +112,16 @@ func (m *Manager) GetSessionDir() string {
 	return m.sessionDir
 }
 
+// ResumeRecorder reopens this session's terminal.Recorder after a
+// reconnect, appending to its existing log files and continuing
+// scriptreplay timing from where the prior Recorder instance for this
+// directory left off instead of truncating and restarting it (see
+// terminal.ResumeRecorder). The caller is responsible for having already
+// validated the reconnect token via m.state.CanReconnect.
+func (m *Manager) ResumeRecorder(lastSeqNo int64, flushInterval time.Duration, sinks []terminal.RecorderSink, sinkBufferSize int) (*terminal.Recorder, error) {
+	return terminal.ResumeRecorder(m.sessionDir, m.session.ID, lastSeqNo, flushInterval, sinks, sinkBufferSize)
+}
+
 // GetFilePath returns the full path for a session file
 func (m *Manager) GetFilePath(filename string) string {
 	return filepath.Join(m.sessionDir, filename)
@@ -165,6 +186,47 @@ func (m *Manager) Complete() error {
 	}
 
 	log.Println("Session: All files protected (read-only, root access only)")
+
+	if err := m.uploadArtifacts(); err != nil {
+		log.Printf("Warning: Could not upload session artifacts: %v", err)
+	}
+
+	return nil
+}
+
+// uploadArtifacts pushes the recorder log, extracted commands, anti-cheat
+// report, and asciicast to the configured SessionStore under
+// candidate/{name}/{sessionID}/. With the default LocalStore this writes a
+// second copy of each artifact under baseDir/candidate/{name}/{sessionID}/;
+// with a remote SessionStore (e.g. S3Store, wired in from main.go) it
+// uploads them.
+func (m *Manager) uploadArtifacts() error {
+	if m.store == nil {
+		return nil
+	}
+
+	prefix := fmt.Sprintf("candidate/%s/%s", m.session.CandidateName, m.session.ID)
+	artifacts := []string{"terminal.log", "commands.log", "analysis.json", "session.cast"}
+
+	for _, filename := range artifacts {
+		localPath := m.GetFilePath(filename)
+
+		file, err := os.Open(localPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to open %s: %w", filename, err)
+		}
+
+		key := fmt.Sprintf("%s/%s", prefix, filename)
+		err = m.store.PutObject(context.Background(), key, file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", filename, err)
+		}
+	}
+
 	return nil
 }
 
@@ -187,6 +249,7 @@ func (m *Manager) calculateFileHashes() error {
 		"websocket.log",
 		"events.log",
 		"commands.log",
+		"session.cast",
 	}
 
 	for _, filename := range files {
@@ -239,3 +302,66 @@ func (m *Manager) VerifyFileHash(filename string) (bool, error) {
 
 	return actualHash == expectedHash, nil
 }
+
+// MergeSlices reads every writer's slice log under sessionDir/slices/ (see
+// terminal.SliceLog) and returns them as a single stream ordered by each
+// slice's first event timestamp. SeqNo only orders slices within one
+// writer's own hash chain, so timestamp is the only ordering available
+// across multiple writer subdirectories (e.g. a reconnect worker alongside
+// the original process).
+func (m *Manager) MergeSlices() ([]terminal.Slice, error) {
+	slicesDir := m.GetFilePath("slices")
+
+	writerDirs, err := os.ReadDir(slicesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read slices directory: %w", err)
+	}
+
+	var slices []terminal.Slice
+
+	for _, writerDir := range writerDirs {
+		if !writerDir.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(slicesDir, writerDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read writer directory %s: %w", writerDir.Name(), err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue // skip ".tmp" partials left by a crash mid-rename
+			}
+
+			data, err := os.ReadFile(filepath.Join(slicesDir, writerDir.Name(), entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read slice %s/%s: %w", writerDir.Name(), entry.Name(), err)
+			}
+
+			var slice terminal.Slice
+			if err := json.Unmarshal(data, &slice); err != nil {
+				return nil, fmt.Errorf("failed to parse slice %s/%s: %w", writerDir.Name(), entry.Name(), err)
+			}
+			slices = append(slices, slice)
+		}
+	}
+
+	sort.Slice(slices, func(i, j int) bool {
+		return firstEventTSNs(slices[i]) < firstEventTSNs(slices[j])
+	})
+
+	return slices, nil
+}
+
+// firstEventTSNs returns the timestamp of a slice's first event, used to
+// order slices from different writers relative to one another.
+func firstEventTSNs(s terminal.Slice) int64 {
+	if len(s.Events) == 0 {
+		return 0
+	}
+	return s.Events[0].TSNs
+}
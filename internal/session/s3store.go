@@ -0,0 +1,80 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Store implements SessionStore against an S3-compatible object storage
+// bucket, so echobox can run as a stateless container whose pod
+// filesystem is ephemeral.
+type S3Store struct {
+	client *s3.Client
+	bucket string
+}
+
+// NewS3Store creates an S3-backed SessionStore against the given bucket.
+// client may point at AWS S3 or any S3-compatible endpoint (MinIO, GCS's
+// S3 interop mode, etc.) via its own configuration.
+func NewS3Store(client *s3.Client, bucket string) *S3Store {
+	return &S3Store{client: client, bucket: bucket}
+}
+
+// PutObject uploads data to s3://bucket/key.
+func (s *S3Store) PutObject(ctx context.Context, key string, data io.Reader) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return fmt.Errorf("failed to buffer %s for upload: %w", key, err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put s3://%s/%s: %w", s.bucket, key, err)
+	}
+
+	return nil
+}
+
+// GetObject downloads s3://bucket/key.
+func (s *S3Store) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", s.bucket, key, err)
+	}
+
+	return out.Body, nil
+}
+
+// List returns every key under prefix in the bucket.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.bucket, prefix, err)
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+
+	return keys, nil
+}
@@ -19,25 +19,36 @@ const (
 // SessionState tracks the runtime state of a session
 type SessionState struct {
 	// Reconnection
-	ReconnectToken   string
-	TokenCreatedAt   time.Time
-	ReconnectWindow  time.Duration
+	ReconnectToken  string
+	TokenCreatedAt  time.Time
+	ReconnectWindow time.Duration
 
 	// Connection tracking
-	State            ConnectionState
-	LastConnectTime  time.Time
+	State              ConnectionState
+	LastConnectTime    time.Time
 	LastDisconnectTime time.Time
-	ConnectionCount  int
-	DisconnectCount  int
-
-	// Terminal state
-	TerminalBuffer   []byte
-	LastCursorPos    CursorPosition
-	TerminalSize     TerminalSize
+	ConnectionCount    int
+	DisconnectCount    int
+
+	// Terminal state: vt is a VT100/ANSI emulator fed every byte of PTY
+	// output, so reconnects replay a syntactically complete, bounded
+	// screen instead of a byte-truncated tail that strands partial CSI
+	// sequences. LastCursorPos mirrors vt's cursor after each update.
+	vt              *vtScreen
+	ScrollbackLines int
+	LastCursorPos   CursorPosition
+	TerminalSize    TerminalSize
 
 	mu sync.RWMutex
 }
 
+// defaultTerminalCols/Rows seed the VT grid before the first resize
+// event arrives from the client.
+const (
+	defaultTerminalCols = 80
+	defaultTerminalRows = 24
+)
+
 // CursorPosition represents terminal cursor position
 type CursorPosition struct {
 	Row int
@@ -50,8 +61,10 @@ type TerminalSize struct {
 	Rows uint16
 }
 
-// NewSessionState creates a new session state
-func NewSessionState(reconnectWindow time.Duration) *SessionState {
+// NewSessionState creates a new session state. scrollbackLines bounds the
+// reconnect replay's scrollback ring independently of how much raw PTY
+// output has been written, replacing the old fixed 100KB byte budget.
+func NewSessionState(reconnectWindow time.Duration, scrollbackLines int) *SessionState {
 	return &SessionState{
 		ReconnectToken:  uuid.New().String(),
 		TokenCreatedAt:  time.Now(),
@@ -59,7 +72,9 @@ func NewSessionState(reconnectWindow time.Duration) *SessionState {
 		State:           StateActive,
 		LastConnectTime: time.Now(),
 		ConnectionCount: 1,
-		TerminalBuffer:  make([]byte, 0),
+		ScrollbackLines: scrollbackLines,
+		vt:              newVTScreen(defaultTerminalCols, defaultTerminalRows, scrollbackLines),
+		TerminalSize:    TerminalSize{Cols: defaultTerminalCols, Rows: defaultTerminalRows},
 	}
 }
 
@@ -143,36 +158,34 @@ func (s *SessionState) GetReconnectToken() string {
 	return s.ReconnectToken
 }
 
-// UpdateTerminalBuffer updates the stored terminal buffer
+// UpdateTerminalBuffer feeds newly written PTY output through the VT
+// emulator and refreshes LastCursorPos from its parsed cursor position.
 func (s *SessionState) UpdateTerminalBuffer(data []byte) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Keep last 100KB of output for reconnection
-	maxBufferSize := 100 * 1024
-	s.TerminalBuffer = append(s.TerminalBuffer, data...)
-
-	// Trim if too large (keep most recent)
-	if len(s.TerminalBuffer) > maxBufferSize {
-		s.TerminalBuffer = s.TerminalBuffer[len(s.TerminalBuffer)-maxBufferSize:]
-	}
+	s.vt.write(data)
+	s.LastCursorPos = CursorPosition{Row: s.vt.cursorRow, Col: s.vt.cursorCol}
 }
 
-// GetTerminalBuffer returns a copy of the terminal buffer
-func (s *SessionState) GetTerminalBuffer() []byte {
+// GetReplayFrame returns a minimal, syntactically complete escape
+// sequence stream that reconstructs the emulator's current visible
+// screen plus its bounded scrollback ring. A client that applies this to
+// a blank terminal sees a coherent view regardless of where a prior raw
+// log would have been truncated mid-sequence.
+func (s *SessionState) GetReplayFrame() []byte {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-
-	buffer := make([]byte, len(s.TerminalBuffer))
-	copy(buffer, s.TerminalBuffer)
-	return buffer
+	return s.vt.replay()
 }
 
-// UpdateTerminalSize updates the terminal dimensions
+// UpdateTerminalSize updates the terminal dimensions and resizes the VT
+// grid to match, preserving as much of the existing screen as fits.
 func (s *SessionState) UpdateTerminalSize(cols, rows uint16) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.TerminalSize = TerminalSize{Cols: cols, Rows: rows}
+	s.vt.resize(int(cols), int(rows))
 }
 
 // GetTerminalSize returns the current terminal size
@@ -188,11 +201,11 @@ func (s *SessionState) GetConnectionStats() map[string]interface{} {
 	defer s.mu.RUnlock()
 
 	return map[string]interface{}{
-		"state":              s.State,
-		"connection_count":   s.ConnectionCount,
-		"disconnect_count":   s.DisconnectCount,
-		"last_connect_time":  s.LastConnectTime,
+		"state":                s.State,
+		"connection_count":     s.ConnectionCount,
+		"disconnect_count":     s.DisconnectCount,
+		"last_connect_time":    s.LastConnectTime,
 		"last_disconnect_time": s.LastDisconnectTime,
-		"token_age_seconds":  time.Since(s.TokenCreatedAt).Seconds(),
+		"token_age_seconds":    time.Since(s.TokenCreatedAt).Seconds(),
 	}
 }
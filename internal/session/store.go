@@ -0,0 +1,98 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SessionStore abstracts where finished session artifacts live, so the
+// same Manager works whether the pod filesystem is durable or the
+// container is stateless and artifacts must go to object storage.
+type SessionStore interface {
+	// PutObject writes data under key, creating any parent structure the
+	// backend needs.
+	PutObject(ctx context.Context, key string, data io.Reader) error
+
+	// GetObject opens key for reading. The caller must close it.
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// List returns every key under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// LocalStore implements SessionStore on the local filesystem, rooted at
+// baseDir. This is the original (and default) behavior of Manager.
+type LocalStore struct {
+	baseDir string
+}
+
+// NewLocalStore creates a filesystem-backed SessionStore rooted at baseDir.
+func NewLocalStore(baseDir string) *LocalStore {
+	return &LocalStore{baseDir: baseDir}
+}
+
+// PutObject writes data to baseDir/key, creating parent directories as
+// needed.
+func (l *LocalStore) PutObject(ctx context.Context, key string, data io.Reader) error {
+	path := filepath.Join(l.baseDir, key)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", key, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// GetObject opens baseDir/key for reading.
+func (l *LocalStore) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	path := filepath.Join(l.baseDir, key)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+
+	return file, nil
+}
+
+// List returns every file path under baseDir/prefix, relative to baseDir.
+func (l *LocalStore) List(ctx context.Context, prefix string) ([]string, error) {
+	root := filepath.Join(l.baseDir, prefix)
+
+	var keys []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.baseDir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,537 @@
+package session
+
+import "fmt"
+
+// vtCell is a single screen cell: the rune occupying it plus the SGR
+// attributes active when it was written.
+type vtCell struct {
+	ch      rune
+	fg      int
+	bg      int
+	bold    bool
+	reverse bool
+}
+
+var blankCell = vtCell{ch: ' ', fg: -1, bg: -1}
+
+// vtParseState tracks where we are in an in-flight escape sequence.
+type vtParseState int
+
+const (
+	vtStateNormal vtParseState = iota
+	vtStateEscape
+	vtStateCSI
+	vtStateOSC
+)
+
+// vtScreen is a small in-tree VT100/ANSI emulator. It exists so
+// SessionState can replay a syntactically complete, bounded screen on
+// reconnect instead of dumping a byte-truncated tail of raw PTY output,
+// which strands partial CSI sequences and mis-renders any nontrivial TUI
+// (vim, htop, less). It implements only the subset of the standard that
+// candidate-facing shells and the tools above actually emit: cursor
+// movement, erase, SGR color/bold/reverse, scroll regions, and the
+// DEC private modes used for the alternate screen.
+type vtScreen struct {
+	cols, rows int
+	grid       [][]vtCell
+
+	altGrid   [][]vtCell
+	altActive bool
+
+	scrollback    [][]vtCell
+	scrollbackMax int
+
+	cursorRow, cursorCol           int
+	savedCursorRow, savedCursorCol int
+
+	curFG, curBG        int
+	curBold, curReverse bool
+
+	scrollTop, scrollBottom int // 0-based, inclusive
+
+	state      vtParseState
+	csiParams  []int
+	csiPrivate bool
+}
+
+func newVTScreen(cols, rows, scrollbackMax int) *vtScreen {
+	v := &vtScreen{
+		cols:          cols,
+		rows:          rows,
+		scrollbackMax: scrollbackMax,
+		curFG:         -1,
+		curBG:         -1,
+	}
+	v.grid = newVTGrid(cols, rows)
+	v.scrollBottom = rows - 1
+	return v
+}
+
+func newVTGrid(cols, rows int) [][]vtCell {
+	grid := make([][]vtCell, rows)
+	for r := range grid {
+		grid[r] = newVTRow(cols)
+	}
+	return grid
+}
+
+func newVTRow(cols int) []vtCell {
+	row := make([]vtCell, cols)
+	for i := range row {
+		row[i] = blankCell
+	}
+	return row
+}
+
+// resize reallocates the grid to the new dimensions, preserving as much
+// of the existing top-left content as fits. This is a best-effort
+// reflow, not true line-wrapping reflow: a full terminal would re-wrap
+// long logical lines, but that requires tracking wrap points the source
+// programs don't give us.
+func (v *vtScreen) resize(cols, rows int) {
+	if cols == v.cols && rows == v.rows {
+		return
+	}
+
+	newGrid := newVTGrid(cols, rows)
+	for r := 0; r < rows && r < len(v.grid); r++ {
+		copy(newGrid[r], v.grid[r])
+	}
+	v.grid = newGrid
+
+	if v.altGrid != nil {
+		newAltGrid := newVTGrid(cols, rows)
+		for r := 0; r < rows && r < len(v.altGrid); r++ {
+			copy(newAltGrid[r], v.altGrid[r])
+		}
+		v.altGrid = newAltGrid
+	}
+
+	v.cols = cols
+	v.rows = rows
+	v.scrollBottom = rows - 1
+	if v.scrollTop >= rows {
+		v.scrollTop = 0
+	}
+	if v.cursorRow >= rows {
+		v.cursorRow = rows - 1
+	}
+	if v.cursorCol >= cols {
+		v.cursorCol = cols - 1
+	}
+}
+
+func (v *vtScreen) activeGrid() [][]vtCell {
+	if v.altActive {
+		return v.altGrid
+	}
+	return v.grid
+}
+
+// write feeds a chunk of raw PTY output through the parser.
+func (v *vtScreen) write(data []byte) {
+	for _, b := range data {
+		v.step(b)
+	}
+}
+
+func (v *vtScreen) step(b byte) {
+	switch v.state {
+	case vtStateNormal:
+		v.stepNormal(b)
+	case vtStateEscape:
+		v.stepEscape(b)
+	case vtStateCSI:
+		v.stepCSI(b)
+	case vtStateOSC:
+		// OSC (window title, etc.) carries no screen-visible state;
+		// just consume until its terminator (BEL, or ESC \ whose '\'
+		// stepEscape drops).
+		if b == 0x07 {
+			v.state = vtStateNormal
+		} else if b == 0x1B {
+			v.state = vtStateEscape
+		}
+	}
+}
+
+func (v *vtScreen) stepNormal(b byte) {
+	switch b {
+	case 0x1B:
+		v.state = vtStateEscape
+	case '\r':
+		v.cursorCol = 0
+	case '\n':
+		v.lineFeed()
+	case '\b':
+		if v.cursorCol > 0 {
+			v.cursorCol--
+		}
+	case '\t':
+		v.cursorCol = ((v.cursorCol / 8) + 1) * 8
+		if v.cursorCol >= v.cols {
+			v.cursorCol = v.cols - 1
+		}
+	default:
+		if b >= 0x20 {
+			v.put(rune(b))
+		}
+	}
+}
+
+func (v *vtScreen) stepEscape(b byte) {
+	switch b {
+	case '[':
+		v.state = vtStateCSI
+		v.csiParams = v.csiParams[:0]
+		v.csiPrivate = false
+	case ']':
+		v.state = vtStateOSC
+	case '7':
+		v.savedCursorRow, v.savedCursorCol = v.cursorRow, v.cursorCol
+		v.state = vtStateNormal
+	case '8':
+		v.cursorRow, v.cursorCol = v.savedCursorRow, v.savedCursorCol
+		v.state = vtStateNormal
+	case 'D':
+		v.lineFeed()
+		v.state = vtStateNormal
+	case 'M':
+		v.reverseLineFeed()
+		v.state = vtStateNormal
+	case 'c':
+		v.reset()
+		v.state = vtStateNormal
+	default:
+		v.state = vtStateNormal
+	}
+}
+
+func (v *vtScreen) stepCSI(b byte) {
+	switch {
+	case b == '?' && len(v.csiParams) == 0:
+		v.csiPrivate = true
+	case b >= '0' && b <= '9':
+		if len(v.csiParams) == 0 {
+			v.csiParams = append(v.csiParams, 0)
+		}
+		last := len(v.csiParams) - 1
+		v.csiParams[last] = v.csiParams[last]*10 + int(b-'0')
+	case b == ';':
+		v.csiParams = append(v.csiParams, 0)
+	default:
+		v.execCSI(b)
+		v.state = vtStateNormal
+	}
+}
+
+func (v *vtScreen) param(i, def int) int {
+	if i >= len(v.csiParams) || v.csiParams[i] == 0 {
+		return def
+	}
+	return v.csiParams[i]
+}
+
+func (v *vtScreen) execCSI(final byte) {
+	if v.csiPrivate {
+		v.execPrivateMode(final)
+		return
+	}
+
+	switch final {
+	case 'A':
+		v.cursorRow -= v.param(0, 1)
+	case 'B':
+		v.cursorRow += v.param(0, 1)
+	case 'C':
+		v.cursorCol += v.param(0, 1)
+	case 'D':
+		v.cursorCol -= v.param(0, 1)
+	case 'H', 'f':
+		v.cursorRow = v.param(0, 1) - 1
+		v.cursorCol = v.param(1, 1) - 1
+	case 'J':
+		v.eraseDisplay(v.param(0, 0))
+	case 'K':
+		v.eraseLine(v.param(0, 0))
+	case 'm':
+		v.applySGR()
+	case 'r':
+		v.scrollTop = v.param(0, 1) - 1
+		v.scrollBottom = v.param(1, v.rows) - 1
+	case 'S':
+		for i := 0; i < v.param(0, 1); i++ {
+			v.scrollUp()
+		}
+	case 'T':
+		for i := 0; i < v.param(0, 1); i++ {
+			v.scrollDown()
+		}
+	}
+
+	v.clampCursor()
+}
+
+// execPrivateMode handles the DEC private ('?'-prefixed) mode set (h) and
+// reset (l) sequences. Only the alternate-screen modes affect rendering;
+// cursor-visibility and mouse-tracking modes are parsed but have no
+// effect on the replayed grid.
+func (v *vtScreen) execPrivateMode(final byte) {
+	if final != 'h' && final != 'l' {
+		return
+	}
+	enable := final == 'h'
+
+	for _, mode := range v.csiParams {
+		switch mode {
+		case 47, 1047, 1049:
+			v.setAltScreen(enable)
+		}
+	}
+}
+
+func (v *vtScreen) setAltScreen(enable bool) {
+	if enable == v.altActive {
+		return
+	}
+	if enable {
+		v.altGrid = newVTGrid(v.cols, v.rows)
+	}
+	v.altActive = enable
+}
+
+func (v *vtScreen) applySGR() {
+	if len(v.csiParams) == 0 {
+		v.csiParams = []int{0}
+	}
+	for _, p := range v.csiParams {
+		switch {
+		case p == 0:
+			v.curFG, v.curBG, v.curBold, v.curReverse = -1, -1, false, false
+		case p == 1:
+			v.curBold = true
+		case p == 22:
+			v.curBold = false
+		case p == 7:
+			v.curReverse = true
+		case p == 27:
+			v.curReverse = false
+		case p == 39:
+			v.curFG = -1
+		case p == 49:
+			v.curBG = -1
+		case p >= 30 && p <= 37:
+			v.curFG = p - 30
+		case p >= 40 && p <= 47:
+			v.curBG = p - 40
+		case p >= 90 && p <= 97:
+			v.curFG = p - 90 + 8
+		case p >= 100 && p <= 107:
+			v.curBG = p - 100 + 8
+		}
+	}
+}
+
+func (v *vtScreen) put(r rune) {
+	if v.cursorCol >= v.cols {
+		v.cursorCol = 0
+		v.lineFeed()
+	}
+
+	grid := v.activeGrid()
+	grid[v.cursorRow][v.cursorCol] = vtCell{
+		ch:      r,
+		fg:      v.curFG,
+		bg:      v.curBG,
+		bold:    v.curBold,
+		reverse: v.curReverse,
+	}
+	v.cursorCol++
+}
+
+func (v *vtScreen) lineFeed() {
+	if v.cursorRow == v.scrollBottom {
+		v.scrollUp()
+		return
+	}
+	v.cursorRow++
+	v.clampCursor()
+}
+
+func (v *vtScreen) reverseLineFeed() {
+	if v.cursorRow == v.scrollTop {
+		v.scrollDown()
+		return
+	}
+	v.cursorRow--
+	v.clampCursor()
+}
+
+// scrollUp moves every line in the scroll region up by one, pushing the
+// line that falls off the top of the *primary* screen into scrollback
+// (the alternate screen, used by full-screen TUIs, has no scrollback).
+func (v *vtScreen) scrollUp() {
+	grid := v.activeGrid()
+	if !v.altActive && v.scrollTop == 0 && v.scrollbackMax > 0 {
+		v.pushScrollback(grid[v.scrollTop])
+	}
+	for r := v.scrollTop; r < v.scrollBottom; r++ {
+		grid[r] = grid[r+1]
+	}
+	grid[v.scrollBottom] = newVTRow(v.cols)
+}
+
+func (v *vtScreen) scrollDown() {
+	grid := v.activeGrid()
+	for r := v.scrollBottom; r > v.scrollTop; r-- {
+		grid[r] = grid[r-1]
+	}
+	grid[v.scrollTop] = newVTRow(v.cols)
+}
+
+func (v *vtScreen) pushScrollback(line []vtCell) {
+	cp := make([]vtCell, len(line))
+	copy(cp, line)
+	v.scrollback = append(v.scrollback, cp)
+	if len(v.scrollback) > v.scrollbackMax {
+		v.scrollback = v.scrollback[len(v.scrollback)-v.scrollbackMax:]
+	}
+}
+
+func (v *vtScreen) eraseDisplay(mode int) {
+	grid := v.activeGrid()
+	switch mode {
+	case 0:
+		v.eraseLine(0)
+		for r := v.cursorRow + 1; r < v.rows; r++ {
+			grid[r] = newVTRow(v.cols)
+		}
+	case 1:
+		v.eraseLine(1)
+		for r := 0; r < v.cursorRow; r++ {
+			grid[r] = newVTRow(v.cols)
+		}
+	case 2, 3:
+		for r := 0; r < v.rows; r++ {
+			grid[r] = newVTRow(v.cols)
+		}
+	}
+}
+
+func (v *vtScreen) eraseLine(mode int) {
+	grid := v.activeGrid()
+	row := grid[v.cursorRow]
+	switch mode {
+	case 0:
+		for c := v.cursorCol; c < v.cols; c++ {
+			row[c] = blankCell
+		}
+	case 1:
+		for c := 0; c <= v.cursorCol && c < v.cols; c++ {
+			row[c] = blankCell
+		}
+	case 2:
+		for c := 0; c < v.cols; c++ {
+			row[c] = blankCell
+		}
+	}
+}
+
+func (v *vtScreen) clampCursor() {
+	if v.cursorRow < 0 {
+		v.cursorRow = 0
+	}
+	if v.cursorRow >= v.rows {
+		v.cursorRow = v.rows - 1
+	}
+	if v.cursorCol < 0 {
+		v.cursorCol = 0
+	}
+	if v.cursorCol >= v.cols {
+		v.cursorCol = v.cols - 1
+	}
+}
+
+func (v *vtScreen) reset() {
+	v.grid = newVTGrid(v.cols, v.rows)
+	v.altGrid = nil
+	v.altActive = false
+	v.cursorRow, v.cursorCol = 0, 0
+	v.curFG, v.curBG = -1, -1
+	v.curBold, v.curReverse = false, false
+	v.scrollTop, v.scrollBottom = 0, v.rows-1
+}
+
+// replay renders scrollback plus the current visible grid as a minimal,
+// syntactically complete escape sequence stream: a full clear, each
+// scrollback line emitted as a preceding newline-terminated row, the
+// visible screen painted cell-by-cell with SGR changes coalesced, and a
+// final cursor position. Replaying this on a fresh client terminal
+// reconstructs exactly what the candidate's screen looked like,
+// regardless of where byte-truncation would otherwise have cut a raw log.
+func (v *vtScreen) replay() []byte {
+	var out []byte
+	out = append(out, "\x1b[2J\x1b[H"...)
+
+	for _, line := range v.scrollback {
+		out = appendVTLine(out, line)
+		out = append(out, '\n')
+	}
+
+	grid := v.activeGrid()
+	curFG, curBG, curBold, curReverse := -1, -1, false, false
+	for r, row := range grid {
+		if r > 0 {
+			out = append(out, "\r\n"...)
+		}
+		for _, cell := range row {
+			if cell.fg != curFG || cell.bg != curBG || cell.bold != curBold || cell.reverse != curReverse {
+				out = append(out, sgrSequence(cell)...)
+				curFG, curBG, curBold, curReverse = cell.fg, cell.bg, cell.bold, cell.reverse
+			}
+			out = append(out, string(cell.ch)...)
+		}
+	}
+
+	out = append(out, []byte(fmt.Sprintf("\x1b[%d;%dH", v.cursorRow+1, v.cursorCol+1))...)
+	return out
+}
+
+func appendVTLine(out []byte, line []vtCell) []byte {
+	for _, cell := range line {
+		out = append(out, string(cell.ch)...)
+	}
+	return out
+}
+
+func sgrSequence(cell vtCell) string {
+	seq := "\x1b[0"
+	if cell.bold {
+		seq += ";1"
+	}
+	if cell.reverse {
+		seq += ";7"
+	}
+	if cell.fg >= 0 {
+		seq += fmt.Sprintf(";%d", fgCode(cell.fg))
+	}
+	if cell.bg >= 0 {
+		seq += fmt.Sprintf(";%d", bgCode(cell.bg))
+	}
+	return seq + "m"
+}
+
+func fgCode(color int) int {
+	if color < 8 {
+		return 30 + color
+	}
+	return 90 + (color - 8)
+}
+
+func bgCode(color int) int {
+	if color < 8 {
+		return 40 + color
+	}
+	return 100 + (color - 8)
+}
@@ -0,0 +1,135 @@
+package session
+
+import "testing"
+
+// cellText renders a vtScreen's active grid as plain lines, ignoring SGR
+// attributes, for assertions that only care about character placement.
+func cellText(v *vtScreen) []string {
+	grid := v.activeGrid()
+	lines := make([]string, len(grid))
+	for r, row := range grid {
+		s := make([]rune, len(row))
+		for c, cell := range row {
+			s[c] = cell.ch
+		}
+		lines[r] = string(s)
+	}
+	return lines
+}
+
+func TestVTScreenWritesPlainText(t *testing.T) {
+	v := newVTScreen(10, 3, 0)
+	v.write([]byte("hello\r\nworld"))
+
+	lines := cellText(v)
+	if got := lines[0][:5]; got != "hello" {
+		t.Fatalf("line 0 = %q, want %q", got, "hello")
+	}
+	if got := lines[1][:5]; got != "world" {
+		t.Fatalf("line 1 = %q, want %q", got, "world")
+	}
+}
+
+func TestVTScreenCursorPositioning(t *testing.T) {
+	v := newVTScreen(10, 3, 0)
+	v.write([]byte("\x1b[2;3Hx"))
+
+	lines := cellText(v)
+	if lines[1][2] != 'x' {
+		t.Fatalf("CSI 2;3H then 'x' should land at row 1, col 2; got row %q", lines[1])
+	}
+}
+
+func TestVTScreenSGRAppliesBoldAndColor(t *testing.T) {
+	v := newVTScreen(10, 3, 0)
+	v.write([]byte("\x1b[1;31mx\x1b[0my"))
+
+	grid := v.activeGrid()
+	if !grid[0][0].bold {
+		t.Fatalf("cell 0 should be bold after CSI 1;31m")
+	}
+	if grid[0][0].fg != 1 {
+		t.Fatalf("cell 0 fg = %d, want 1 (red)", grid[0][0].fg)
+	}
+	if grid[0][1].bold || grid[0][1].fg != -1 {
+		t.Fatalf("cell 1 should have reset attributes after CSI 0m, got bold=%v fg=%d", grid[0][1].bold, grid[0][1].fg)
+	}
+}
+
+// TestVTScreenAltScreenIsolatesContent checks that entering the alternate
+// screen (mode 1049, as full-screen TUIs like vim/htop use) hides the
+// primary screen's content and that leaving it restores the original.
+func TestVTScreenAltScreenIsolatesContent(t *testing.T) {
+	v := newVTScreen(10, 3, 0)
+	v.write([]byte("primary"))
+
+	v.write([]byte("\x1b[?1049h"))
+	if !v.altActive {
+		t.Fatalf("altActive should be true after CSI ?1049h")
+	}
+	v.write([]byte("\x1b[1;1Halt"))
+	if lines := cellText(v); lines[0][:3] != "alt" {
+		t.Fatalf("alt screen line 0 = %q, want prefix %q", lines[0], "alt")
+	}
+
+	v.write([]byte("\x1b[?1049l"))
+	if v.altActive {
+		t.Fatalf("altActive should be false after CSI ?1049l")
+	}
+	if lines := cellText(v); lines[0][:7] != "primary" {
+		t.Fatalf("primary screen should still read %q after leaving alt screen, got %q", "primary", lines[0])
+	}
+}
+
+// TestVTScreenResizePreservesTopLeftAndAltGrid checks that resize copies
+// existing content into the new grid for both the primary and, when
+// active, the alternate screen (the alt-screen resize bug chunk1-3's
+// review fixed).
+func TestVTScreenResizePreservesTopLeftAndAltGrid(t *testing.T) {
+	v := newVTScreen(10, 3, 0)
+	v.write([]byte("\x1b[?1049h"))
+	v.write([]byte("alt-text"))
+
+	v.resize(20, 5)
+
+	if v.cols != 20 || v.rows != 5 {
+		t.Fatalf("dimensions after resize = %dx%d, want 20x5", v.cols, v.rows)
+	}
+	if len(v.altGrid) != 5 || len(v.altGrid[0]) != 20 {
+		t.Fatalf("altGrid dimensions = %dx%d, want 5x20", len(v.altGrid), len(v.altGrid[0]))
+	}
+	if lines := cellText(v); lines[0][:8] != "alt-text" {
+		t.Fatalf("alt screen content should survive resize, got %q", lines[0])
+	}
+}
+
+// TestVTScreenScrollbackAccumulatesOnPrimaryOnly checks that scrolling
+// past the top of the scroll region pushes lines into scrollback on the
+// primary screen but not on the alternate screen, per scrollUp's doc
+// comment.
+func TestVTScreenScrollbackAccumulatesOnPrimaryOnly(t *testing.T) {
+	v := newVTScreen(10, 2, 5)
+	v.write([]byte("line1\r\nline2\r\nline3"))
+
+	if len(v.scrollback) != 1 {
+		t.Fatalf("scrollback len = %d, want 1 (one line scrolled off a 2-row screen)", len(v.scrollback))
+	}
+
+	v.write([]byte("\x1b[?1049h"))
+	before := len(v.scrollback)
+	v.write([]byte("a\r\nb\r\nc"))
+	if len(v.scrollback) != before {
+		t.Fatalf("scrollback should not grow while the alternate screen is active")
+	}
+}
+
+func TestVTScreenReplayProducesCursorPosition(t *testing.T) {
+	v := newVTScreen(10, 3, 0)
+	v.write([]byte("\x1b[2;3Hx"))
+
+	out := v.replay()
+	want := "\x1b[2;4H"
+	if len(out) < len(want) || string(out[len(out)-len(want):]) != want {
+		t.Fatalf("replay output should end with cursor position %q, got %q", want, out)
+	}
+}
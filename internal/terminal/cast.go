@@ -0,0 +1,141 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// castHeader is the asciicast v2 header line, written once at the top of
+// the .cast file.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastWriter records a session as an asciicast v2 file, in parallel with
+// the existing scriptreplay-format Recorder. This gives interviewers a
+// portable artifact playable by any asciinema player.
+type CastWriter struct {
+	file      *os.File
+	writer    *bufio.Writer
+	startTime time.Time
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewCastWriter creates the session.cast file and writes its header.
+func NewCastWriter(sessionDir string, width, height int, env map[string]string) (*CastWriter, error) {
+	file, err := os.OpenFile(
+		fmt.Sprintf("%s/session.cast", sessionDir),
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		0600,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session.cast: %w", err)
+	}
+
+	c := &CastWriter{
+		file:      file,
+		writer:    bufio.NewWriter(file),
+		startTime: time.Now(),
+	}
+
+	header := castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: c.startTime.Unix(),
+		Env:       env,
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to marshal cast header: %w", err)
+	}
+
+	if _, err := c.writer.Write(append(headerBytes, '\n')); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write cast header: %w", err)
+	}
+
+	log.Printf("CastWriter: Started asciicast recording in %s", sessionDir)
+	return c, nil
+}
+
+// RecordOutput appends a PTY output frame.
+func (c *CastWriter) RecordOutput(data []byte) error {
+	return c.writeFrame("o", string(data))
+}
+
+// RecordInput appends a keystroke input frame.
+func (c *CastWriter) RecordInput(data []byte) error {
+	return c.writeFrame("i", string(data))
+}
+
+// RecordResize appends a resize marker frame. It is intended to be
+// registered with PTY.OnResize.
+func (c *CastWriter) RecordResize(cols, rows uint16) {
+	if err := c.writeFrame("r", fmt.Sprintf("%dx%d", cols, rows)); err != nil {
+		log.Printf("CastWriter: failed to record resize: %v", err)
+	}
+}
+
+// writeFrame writes a single [time, type, data] event line.
+func (c *CastWriter) writeFrame(eventType, payload string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return fmt.Errorf("cast writer is closed")
+	}
+
+	elapsed := time.Since(c.startTime).Seconds()
+	frame, err := json.Marshal([]interface{}{elapsed, eventType, payload})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cast frame: %w", err)
+	}
+
+	_, err = c.writer.Write(append(frame, '\n'))
+	return err
+}
+
+// Flush flushes the buffered writer to disk.
+func (c *CastWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	if err := c.writer.Flush(); err != nil {
+		return err
+	}
+	return c.file.Sync()
+}
+
+// Close flushes and closes the cast file.
+func (c *CastWriter) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	if err := c.writer.Flush(); err != nil {
+		log.Printf("CastWriter: flush error: %v", err)
+	}
+
+	return c.file.Close()
+}
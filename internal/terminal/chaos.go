@@ -0,0 +1,111 @@
+package terminal
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ChaosConfig describes the degraded-link conditions Chaos should
+// simulate. A zero value is a no-op passthrough.
+type ChaosConfig struct {
+	LatencyMS int     `json:"latency_ms"`
+	JitterMS  int     `json:"jitter_ms"`
+	BWBps     int     `json:"bw_bps"`
+	LossPct   float64 `json:"loss_pct"`
+}
+
+// Chaos wraps a PTY's Read/Write with injected latency, jitter, bandwidth
+// caps, and byte loss, so interviewers can simulate a degraded SSH link
+// mid-session and observe how a candidate reacts.
+type Chaos struct {
+	rw io.ReadWriter
+
+	mu  sync.RWMutex
+	cfg ChaosConfig
+}
+
+// NewChaos wraps rw (typically a *PTY) with a disabled-by-default Chaos
+// filter; call SetConfig to start injecting impairments.
+func NewChaos(rw io.ReadWriter) *Chaos {
+	return &Chaos{rw: rw}
+}
+
+// SetConfig updates the simulated impairments. Safe to call concurrently
+// with Read/Write.
+func (c *Chaos) SetConfig(cfg ChaosConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+}
+
+// Config returns the currently active impairment settings.
+func (c *Chaos) Config() ChaosConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg
+}
+
+// Read reads from the underlying PTY, then applies the configured delay
+// and byte loss to the chunk it returns.
+func (c *Chaos) Read(p []byte) (int, error) {
+	n, err := c.rw.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	cfg := c.Config()
+	c.delay(cfg, n)
+
+	if cfg.LossPct > 0 {
+		n = applyLoss(p[:n], cfg.LossPct)
+	}
+
+	return n, err
+}
+
+// Write applies the configured delay before forwarding to the underlying
+// PTY; writes are not dropped, since losing candidate keystrokes (rather
+// than terminal echo) would be confusing rather than realistic.
+func (c *Chaos) Write(p []byte) (int, error) {
+	c.delay(c.Config(), len(p))
+	return c.rw.Write(p)
+}
+
+// delay sleeps for the configured base latency plus jitter plus the time
+// n bytes would take at the configured bandwidth cap.
+func (c *Chaos) delay(cfg ChaosConfig, n int) {
+	if cfg.LatencyMS == 0 && cfg.JitterMS == 0 && cfg.BWBps == 0 {
+		return
+	}
+
+	d := time.Duration(cfg.LatencyMS) * time.Millisecond
+
+	if cfg.JitterMS > 0 {
+		jitter := rand.Intn(2*cfg.JitterMS+1) - cfg.JitterMS
+		d += time.Duration(jitter) * time.Millisecond
+	}
+
+	if cfg.BWBps > 0 {
+		d += time.Duration(float64(n) / float64(cfg.BWBps) * float64(time.Second))
+	}
+
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// applyLoss drops bytes from data in place at roughly lossPct percent,
+// returning the new (possibly shorter) length.
+func applyLoss(data []byte, lossPct float64) int {
+	kept := 0
+	for _, b := range data {
+		if rand.Float64()*100 < lossPct {
+			continue
+		}
+		data[kept] = b
+		kept++
+	}
+	return kept
+}
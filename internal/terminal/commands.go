@@ -0,0 +1,283 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// commandEntry is one reconstructed shell command, written as a line of
+// commands.log.
+type commandEntry struct {
+	TSMs        int64  `json:"ts_ms"`
+	Prompt      string `json:"prompt"`
+	Cmd         string `json:"cmd"`
+	ExitCode    *int   `json:"exit_code"`
+	DurationMs  int64  `json:"duration_ms"`
+	OutputBytes int    `json:"output_bytes"`
+}
+
+// promptRe matches a rendered terminal line of the form "<prompt><ws><cmd>"
+// for common bash/zsh PS1 shapes - anything ending in an unescaped "$" or
+// "#" followed by the typed command. It's a heuristic, not a shell
+// parser: it will occasionally misfire on program output that happens to
+// end a line in "$" or "#", which is an accepted tradeoff for not having
+// to special-case every PS1 a candidate might configure.
+var promptRe = regexp.MustCompile(`^(.*[$#])\s+(\S.*)$`)
+
+// continuationRe matches the PS2 continuation prompt bash/zsh show while
+// reading a multi-line command (unclosed quote, trailing backslash, etc).
+var continuationRe = regexp.MustCompile(`^>\s+(.*)$`)
+
+// oscExitRe finds OSC 133;D[;exit_code] shell-integration markers, when a
+// candidate's shell emits them.
+var oscExitRe = regexp.MustCompile(`\x1b\]133;D(?:;(\d+))?`)
+
+// ExtractCommands reconstructs the commands a candidate ran from
+// terminal.log (raw PTY output) and timing.log (per-write elapsed time),
+// writing commands.log as JSONL. It runs terminal.log through a minimal
+// VT100/xterm state machine - just enough of CSI/OSC/SGR handling to
+// track cursor movement and line erasure - to recover the actual rendered
+// text of each line the way a terminal would have displayed it, then
+// splits rendered lines into prompt/command pairs with a PS1 heuristic.
+func ExtractCommands(sessionDir string) error {
+	terminalData, err := os.ReadFile(fmt.Sprintf("%s/terminal.log", sessionDir))
+	if err != nil {
+		return fmt.Errorf("failed to read terminal.log: %w", err)
+	}
+
+	checkpoints, err := buildOffsetTimeline(fmt.Sprintf("%s/timing.log", sessionDir))
+	if err != nil {
+		return fmt.Errorf("failed to read timing.log: %w", err)
+	}
+
+	lines := renderLines(terminalData)
+	exitMarkers := findExitMarkers(terminalData)
+	commands := reconstructCommands(lines, exitMarkers, checkpoints)
+
+	commandsPath := fmt.Sprintf("%s/commands.log", sessionDir)
+	file, err := os.Create(commandsPath)
+	if err != nil {
+		return fmt.Errorf("failed to create commands.log: %w", err)
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, cmd := range commands {
+		if err := enc.Encode(cmd); err != nil {
+			return fmt.Errorf("failed to write command entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// renderedLine is one line of terminal.log as it would have been
+// displayed, along with the byte offset in terminal.log at which it was
+// finalized (its trailing newline).
+type renderedLine struct {
+	text       string
+	byteOffset int
+}
+
+// renderLines runs data through lineBuffer and returns every line
+// finalized by a line feed. Any content left in the final, unterminated
+// row is appended too, so trailing output without a final newline isn't
+// silently dropped.
+func renderLines(data []byte) []renderedLine {
+	b := newLineBuffer()
+	b.write(data)
+	if last := b.lineText(b.cursorRow); last != "" {
+		b.lines = append(b.lines, renderedLine{text: last, byteOffset: len(data)})
+	}
+	return b.lines
+}
+
+// findExitMarkers scans raw terminal.log bytes for OSC 133;D shell
+// integration markers, returning each marker's byte offset and, if
+// present, the exit code the shell reported.
+func findExitMarkers(data []byte) []exitMarker {
+	var markers []exitMarker
+	for _, loc := range oscExitRe.FindAllSubmatchIndex(data, -1) {
+		marker := exitMarker{byteOffset: loc[0], code: -1}
+		if loc[2] >= 0 {
+			if code, err := strconv.Atoi(string(data[loc[2]:loc[3]])); err == nil {
+				marker.code = code
+			}
+		}
+		markers = append(markers, marker)
+	}
+	return markers
+}
+
+type exitMarker struct {
+	byteOffset int
+	code       int // -1 if the marker carried no exit code
+}
+
+// offsetCheckpoint records that byteOffset bytes into terminal.log had
+// been written by tsMs milliseconds into the session.
+type offsetCheckpoint struct {
+	byteOffset int
+	tsMs       int64
+}
+
+// buildOffsetTimeline reads timing.log ("<elapsed_seconds> <chunk_bytes>"
+// per Recorder.RecordOutput call) and turns it into a cumulative
+// byte-offset -> elapsed-ms timeline, so any position in terminal.log can
+// be mapped back to when it was written. A missing timing.log (recording
+// disabled, or a pre-existing session) yields an empty timeline rather
+// than an error - callers fall back to ts_ms 0.
+func buildOffsetTimeline(timingPath string) ([]offsetCheckpoint, error) {
+	file, err := os.Open(timingPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var checkpoints []offsetCheckpoint
+	cumBytes := 0
+	cumMs := int64(0)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 2 {
+			continue
+		}
+		secs, err1 := strconv.ParseFloat(parts[0], 64)
+		length, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		cumMs += int64(secs * 1000)
+		cumBytes += length
+		checkpoints = append(checkpoints, offsetCheckpoint{byteOffset: cumBytes, tsMs: cumMs})
+	}
+
+	return checkpoints, scanner.Err()
+}
+
+// tsForOffset returns the elapsed-ms timestamp of the first checkpoint at
+// or after byteOffset - i.e. when the chunk containing that byte arrived.
+func tsForOffset(checkpoints []offsetCheckpoint, byteOffset int) int64 {
+	for _, cp := range checkpoints {
+		if cp.byteOffset > byteOffset {
+			return cp.tsMs
+		}
+	}
+	if len(checkpoints) > 0 {
+		return checkpoints[len(checkpoints)-1].tsMs
+	}
+	return 0
+}
+
+// workingCommand accumulates a commandEntry plus the byte-offset range it
+// spans in terminal.log, so OSC exit markers can be attributed to it.
+type workingCommand struct {
+	entry       commandEntry
+	startOffset int
+	endOffset   int
+}
+
+// reconstructCommands walks the rendered lines in order, treating any
+// line matching promptRe as a new prompt+command pair and everything
+// between prompts as that command's output. Lines matching
+// continuationRe extend the most recently started command instead of
+// starting a new one, handling multi-line commands.
+func reconstructCommands(lines []renderedLine, exitMarkers []exitMarker, checkpoints []offsetCheckpoint) []commandEntry {
+	var working []*workingCommand
+	var pending *workingCommand
+	var pendingOutputBytes int
+
+	finalize := func(endOffset int) {
+		if pending == nil {
+			return
+		}
+		pending.endOffset = endOffset
+		pending.entry.DurationMs = tsForOffset(checkpoints, endOffset) - pending.entry.TSMs
+		if pending.entry.DurationMs < 0 {
+			pending.entry.DurationMs = 0
+		}
+		pending.entry.OutputBytes = pendingOutputBytes
+		working = append(working, pending)
+		pending = nil
+		pendingOutputBytes = 0
+	}
+
+	for _, line := range lines {
+		if m := promptRe.FindStringSubmatch(line.text); m != nil {
+			finalize(line.byteOffset)
+
+			pending = &workingCommand{
+				startOffset: line.byteOffset,
+				entry: commandEntry{
+					TSMs:   tsForOffset(checkpoints, line.byteOffset),
+					Prompt: strings.TrimSpace(m[1]),
+					Cmd:    strings.TrimSpace(m[2]),
+				},
+			}
+			continue
+		}
+
+		if pending == nil {
+			continue
+		}
+
+		if m := continuationRe.FindStringSubmatch(line.text); m != nil {
+			pending.entry.Cmd += "\n" + m[1]
+			continue
+		}
+
+		pendingOutputBytes += len(line.text) + 1
+
+		// "echo $?" immediately after a command reports *that* command's
+		// exit status - fold its numeric output into the previous entry's
+		// ExitCode. The "echo $?" line itself is still kept as an entry.
+		if pending.entry.Cmd == "echo $?" && len(working) > 0 {
+			if code, err := strconv.Atoi(strings.TrimSpace(line.text)); err == nil {
+				working[len(working)-1].entry.ExitCode = &code
+			}
+		}
+	}
+
+	if len(lines) > 0 {
+		finalize(lines[len(lines)-1].byteOffset)
+	}
+
+	applyExitMarkers(working, exitMarkers)
+
+	entries := make([]commandEntry, len(working))
+	for i, w := range working {
+		entries[i] = w.entry
+	}
+	return entries
+}
+
+// applyExitMarkers assigns each OSC 133;D exit-code marker to the command
+// whose byte-offset range contains it, without overwriting an exit code
+// already recovered from an "echo $?" pattern.
+func applyExitMarkers(working []*workingCommand, markers []exitMarker) {
+	for _, marker := range markers {
+		if marker.code < 0 {
+			continue
+		}
+		for _, w := range working {
+			if w.entry.ExitCode != nil {
+				continue
+			}
+			if marker.byteOffset >= w.startOffset && marker.byteOffset < w.endOffset {
+				code := marker.code
+				w.entry.ExitCode = &code
+				break
+			}
+		}
+	}
+}
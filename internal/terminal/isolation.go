@@ -0,0 +1,194 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// capSysAdmin is the capability bit for CAP_SYS_ADMIN, as defined in
+// linux/capability.h.
+const capSysAdmin = 21
+
+// Environment variables used to hand the isolation helper (see
+// RunIsolationHelper) the homeDir/shellUID New would otherwise pass as
+// arguments - the helper is re-exec'd as a bare "self <shell>" command, so
+// there is no argv slot for them.
+const (
+	isolationHelperEnv     = "ECHOBOX_ISOLATION_HELPER"
+	isolationHelperHomeEnv = "ECHOBOX_ISOLATION_HOME"
+	isolationHelperUIDEnv  = "ECHOBOX_ISOLATION_UID"
+)
+
+// IsolationConfig controls how deeply a candidate's shell is sandboxed,
+// beyond the always-on random-UID setuid isolation.
+type IsolationConfig struct {
+	// Namespaces enables PID/mount/UTS/network/IPC namespace isolation
+	// plus a per-session overlayfs root and cgroup v2 limits. It is
+	// downgraded to setuid-only when not running as root (silently) or
+	// when CAP_SYS_ADMIN is unavailable (with a log line).
+	Namespaces bool
+	MemLimitMB int
+	PidsMax    int
+	CPUQuota   float64 // fraction of a CPU core, e.g. 1.5 = 150%
+}
+
+// hasSysAdminCapability reports whether the current process holds
+// CAP_SYS_ADMIN, which CLONE_NEWNS/NEWNET/NEWUTS/NEWIPC and cgroup
+// management require.
+func hasSysAdminCapability() bool {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return false
+		}
+
+		capEff, err := strconv.ParseUint(fields[1], 16, 64)
+		if err != nil {
+			return false
+		}
+
+		return capEff&(1<<capSysAdmin) != 0
+	}
+
+	return false
+}
+
+// RunIsolationHelper checks whether this process was re-exec'd by PTY.New
+// as the isolation helper (see the isolationHelperEnv constants above)
+// and, if so, mounts the candidate's overlayfs root and execs into the
+// real shell, never returning. main should call this before anything else,
+// so a namespaced session's first line of code runs after CLONE_NEWNS has
+// already put it in its own mount namespace - mounting from here, instead
+// of from the parent echobox process, is what makes the overlay visible
+// to the candidate's shell instead of just to the host.
+func RunIsolationHelper() {
+	if os.Getenv(isolationHelperEnv) != "1" {
+		return
+	}
+
+	homeDir := os.Getenv(isolationHelperHomeEnv)
+	shellUID, err := strconv.ParseUint(os.Getenv(isolationHelperUIDEnv), 10, 32)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "isolation helper: invalid %s: %v\n", isolationHelperUIDEnv, err)
+		os.Exit(1)
+	}
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "isolation helper: missing shell argument")
+		os.Exit(1)
+	}
+	shell := os.Args[1]
+
+	if err := bindMountOverlayRoot(homeDir, uint32(shellUID)); err != nil {
+		fmt.Fprintf(os.Stderr, "isolation helper: could not set up overlayfs root: %v\n", err)
+	}
+
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, isolationHelperEnv+"=") ||
+			strings.HasPrefix(kv, isolationHelperHomeEnv+"=") ||
+			strings.HasPrefix(kv, isolationHelperUIDEnv+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	if err := syscall.Exec(shell, []string{shell}, env); err != nil {
+		fmt.Fprintf(os.Stderr, "isolation helper: exec %s: %v\n", shell, err)
+		os.Exit(1)
+	}
+}
+
+// bindMountOverlayRoot mounts an overlayfs at homeDir so the candidate's
+// view of the filesystem is copy-on-write and isolated from the host and
+// other sessions. lower is the read-only base the candidate sees;
+// upper/work live under a scratch directory unique to shellUID. Called
+// from inside RunIsolationHelper, after CLONE_NEWNS has given the calling
+// process its own mount namespace, so this mount is visible to the
+// candidate's shell rather than only to the host.
+func bindMountOverlayRoot(homeDir string, shellUID uint32) error {
+	scratchDir := fmt.Sprintf("/var/lib/echobox/overlay/candidate-%d", shellUID)
+	upperDir := scratchDir + "/upper"
+	workDir := scratchDir + "/work"
+
+	for _, dir := range []string{upperDir, workDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", homeDir, upperDir, workDir)
+	if err := mountOverlay(homeDir, opts); err != nil {
+		return fmt.Errorf("failed to mount overlayfs at %s: %w", homeDir, err)
+	}
+
+	return nil
+}
+
+// confineToCgroup creates a transient cgroup v2 slice for pid and applies
+// the configured memory, pids, and CPU limits, closing the gap where a
+// candidate can otherwise exhaust host memory or fork-bomb the box.
+func confineToCgroup(pid int, shellUID uint32, isolation IsolationConfig) error {
+	cgroupDir := fmt.Sprintf("/sys/fs/cgroup/echobox/candidate-%d", shellUID)
+
+	if err := os.MkdirAll(cgroupDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cgroup %s: %w", cgroupDir, err)
+	}
+
+	if isolation.MemLimitMB > 0 {
+		limit := fmt.Sprintf("%d", isolation.MemLimitMB*1024*1024)
+		if err := writeCgroupFile(cgroupDir, "memory.max", limit); err != nil {
+			return err
+		}
+	}
+
+	if isolation.PidsMax > 0 {
+		if err := writeCgroupFile(cgroupDir, "pids.max", fmt.Sprintf("%d", isolation.PidsMax)); err != nil {
+			return err
+		}
+	}
+
+	if isolation.CPUQuota > 0 {
+		// cpu.max format is "$MAX $PERIOD" in microseconds.
+		const periodUS = 100000
+		quota := int(isolation.CPUQuota * periodUS)
+		if err := writeCgroupFile(cgroupDir, "cpu.max", fmt.Sprintf("%d %d", quota, periodUS)); err != nil {
+			return err
+		}
+	}
+
+	if err := writeCgroupFile(cgroupDir, "cgroup.procs", fmt.Sprintf("%d", pid)); err != nil {
+		return fmt.Errorf("failed to move pid %d into cgroup: %w", pid, err)
+	}
+
+	return nil
+}
+
+// mountOverlay mounts an overlayfs at target with the given lowerdir/
+// upperdir/workdir options.
+func mountOverlay(target, opts string) error {
+	return syscall.Mount("overlay", target, "overlay", 0, opts)
+}
+
+func writeCgroupFile(cgroupDir, filename, value string) error {
+	path := fmt.Sprintf("%s/%s", cgroupDir, filename)
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
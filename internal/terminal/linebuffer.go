@@ -0,0 +1,217 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// lineBuffer is a minimal VT100/xterm state machine used only to
+// reconstruct the rendered text of each terminal line from raw PTY
+// bytes: enough of CSI cursor movement, erase-in-line/display, and OSC
+// skipping to track what a terminal would actually display, without
+// modeling colors, scrollback, or the alternate screen (see
+// session.vtScreen for the full emulator the reconnect/replay path uses).
+type lineBuffer struct {
+	rows      [][]rune
+	cursorRow int
+	cursorCol int
+
+	state  lbState
+	csiBuf strings.Builder
+	oscBuf strings.Builder
+
+	byteOffset int
+	lines      []renderedLine
+}
+
+type lbState int
+
+const (
+	lbNormal lbState = iota
+	lbEscape
+	lbCSI
+	lbOSC
+)
+
+func newLineBuffer() *lineBuffer {
+	return &lineBuffer{rows: [][]rune{{}}}
+}
+
+// write feeds raw terminal.log bytes through the state machine. Byte (not
+// rune) offsets are tracked so exit-code markers and rendered lines can
+// be correlated back to their position in terminal.log; multi-byte UTF-8
+// sequences are carried through as individual bytes rather than decoded,
+// a known approximation for non-ASCII command text.
+func (b *lineBuffer) write(data []byte) {
+	for _, c := range data {
+		b.stepByte(c)
+		b.byteOffset++
+	}
+}
+
+func (b *lineBuffer) stepByte(c byte) {
+	ch := rune(c)
+	switch b.state {
+	case lbNormal:
+		b.stepNormal(ch)
+	case lbEscape:
+		b.stepEscape(ch)
+	case lbCSI:
+		b.stepCSI(ch)
+	case lbOSC:
+		b.stepOSC(ch)
+	}
+}
+
+func (b *lineBuffer) stepNormal(ch rune) {
+	switch ch {
+	case '\x1b':
+		b.state = lbEscape
+	case '\r':
+		b.cursorCol = 0
+	case '\n':
+		b.lines = append(b.lines, renderedLine{text: b.lineText(b.cursorRow), byteOffset: b.byteOffset})
+		b.cursorRow++
+		b.cursorCol = 0
+		b.ensureRow(b.cursorRow)
+	case '\b':
+		if b.cursorCol > 0 {
+			b.cursorCol--
+		}
+	case '\t':
+		b.cursorCol += 8 - (b.cursorCol % 8)
+	default:
+		if ch >= 0x20 {
+			b.put(ch)
+		}
+	}
+}
+
+func (b *lineBuffer) stepEscape(ch rune) {
+	switch ch {
+	case '[':
+		b.csiBuf.Reset()
+		b.state = lbCSI
+	case ']':
+		b.oscBuf.Reset()
+		b.state = lbOSC
+	default:
+		b.state = lbNormal // unsupported single-char escape (e.g. charset select): drop it
+	}
+}
+
+func (b *lineBuffer) stepCSI(ch rune) {
+	if ch >= 0x40 && ch <= 0x7e {
+		b.execCSI(ch, b.csiBuf.String())
+		b.state = lbNormal
+		return
+	}
+	b.csiBuf.WriteRune(ch)
+}
+
+func (b *lineBuffer) execCSI(final rune, params string) {
+	args := parseCSIArgs(params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	switch final {
+	case 'A':
+		b.cursorRow -= arg(0, 1)
+	case 'B':
+		b.cursorRow += arg(0, 1)
+	case 'C':
+		b.cursorCol += arg(0, 1)
+	case 'D':
+		b.cursorCol -= arg(0, 1)
+	case 'G':
+		b.cursorCol = arg(0, 1) - 1
+	case 'H', 'f':
+		b.cursorRow = arg(0, 1) - 1
+		b.cursorCol = arg(1, 1) - 1
+	case 'K':
+		b.eraseLine(arg(0, 0))
+	case 'J':
+		// Extraction only cares about the current line's rendered text,
+		// so erase-in-display is treated the same as erasing the line.
+		b.eraseLine(2)
+	}
+
+	if b.cursorRow < 0 {
+		b.cursorRow = 0
+	}
+	if b.cursorCol < 0 {
+		b.cursorCol = 0
+	}
+	b.ensureRow(b.cursorRow)
+}
+
+func parseCSIArgs(params string) []int {
+	params = strings.TrimLeft(params, "?>=") // private-mode prefixes don't affect cursor/erase handling
+	if params == "" {
+		return nil
+	}
+	parts := strings.Split(params, ";")
+	args := make([]int, len(parts))
+	for i, p := range parts {
+		v, _ := strconv.Atoi(p)
+		args[i] = v
+	}
+	return args
+}
+
+func (b *lineBuffer) eraseLine(mode int) {
+	b.ensureRow(b.cursorRow)
+	row := b.rows[b.cursorRow]
+	switch mode {
+	case 0: // cursor to end of line
+		for i := b.cursorCol; i < len(row); i++ {
+			row[i] = ' '
+		}
+	case 1: // start of line to cursor
+		for i := 0; i <= b.cursorCol && i < len(row); i++ {
+			row[i] = ' '
+		}
+	default: // whole line
+		b.rows[b.cursorRow] = []rune{}
+	}
+}
+
+func (b *lineBuffer) stepOSC(ch rune) {
+	if ch == '\x07' { // BEL
+		b.state = lbNormal
+		return
+	}
+	if ch == '\x1b' { // start of a String Terminator (ESC \); stepEscape drops the '\'
+		b.state = lbEscape
+		return
+	}
+	b.oscBuf.WriteRune(ch)
+}
+
+func (b *lineBuffer) ensureRow(row int) {
+	for len(b.rows) <= row {
+		b.rows = append(b.rows, []rune{})
+	}
+}
+
+func (b *lineBuffer) put(ch rune) {
+	b.ensureRow(b.cursorRow)
+	row := b.rows[b.cursorRow]
+	for len(row) <= b.cursorCol {
+		row = append(row, ' ')
+	}
+	row[b.cursorCol] = ch
+	b.rows[b.cursorRow] = row
+	b.cursorCol++
+}
+
+func (b *lineBuffer) lineText(row int) string {
+	if row < 0 || row >= len(b.rows) {
+		return ""
+	}
+	return strings.TrimRight(string(b.rows[row]), " ")
+}
@@ -15,18 +15,23 @@ import (
 
 // PTY represents a pseudo-terminal
 type PTY struct {
-	file        *os.File
-	cmd         *exec.Cmd
-	mu          sync.Mutex
-	closed      bool
-	readers     []io.Reader
-	writers     []io.Writer
+	file          *os.File
+	cmd           *exec.Cmd
+	mu            sync.Mutex
+	closed        bool
+	readers       []io.Reader
+	writers       []io.Writer
+	resizeHooks   []func(cols, rows uint16)
 	candidateHome string
 }
 
-// New creates a new PTY and spawns the specified shell as the given UID
-// The shell runs as shellUID (random, isolated), while logs stay owned by root
-func New(shell string, shellUID uint32) (*PTY, error) {
+// New creates a new PTY and spawns the specified shell as the given UID.
+// The shell runs as shellUID (random, isolated), while logs stay owned by
+// root. If isolation.Namespaces is set and the process has CAP_SYS_ADMIN,
+// the shell is additionally confined to its own PID/mount/UTS/network/IPC
+// namespaces, an overlayfs root, and a cgroup v2 slice; otherwise it
+// falls back to the setuid-only isolation below.
+func New(shell string, shellUID uint32, isolation IsolationConfig) (*PTY, error) {
 	// Create home directory for this UID if it doesn't exist
 	homeDir := fmt.Sprintf("/home/candidate-%d", shellUID)
 	if err := os.MkdirAll(homeDir+"/solutions", 0755); err != nil {
@@ -49,8 +54,30 @@ func New(shell string, shellUID uint32) (*PTY, error) {
 		}
 	}
 
-	// Create command
-	cmd := exec.Command(shell)
+	// Whether the shell will get its own mount namespace has to be decided
+	// before the command is built: a namespaced shell is launched via the
+	// isolation helper (this same binary, re-exec'd) instead of directly,
+	// so the overlay bind-mount below can happen inside the new namespace -
+	// see RunIsolationHelper.
+	namespaced := false
+	if os.Getuid() == 0 && isolation.Namespaces {
+		if hasSysAdminCapability() {
+			namespaced = true
+		} else {
+			log.Printf("PTY: CAP_SYS_ADMIN unavailable, falling back to setuid-only isolation")
+		}
+	}
+
+	var cmd *exec.Cmd
+	if namespaced {
+		self, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve own executable for isolation helper: %w", err)
+		}
+		cmd = exec.Command(self, shell)
+	} else {
+		cmd = exec.Command(shell)
+	}
 
 	// Set up environment for the shell user
 	cmd.Env = []string{
@@ -60,6 +87,13 @@ func New(shell string, shellUID uint32) (*PTY, error) {
 		fmt.Sprintf("USER=candidate-%d", shellUID),
 		"PATH=/usr/local/bin:/usr/bin:/bin:/usr/sbin:/sbin",
 	}
+	if namespaced {
+		cmd.Env = append(cmd.Env,
+			isolationHelperEnv+"=1",
+			fmt.Sprintf("%s=%s", isolationHelperHomeEnv, homeDir),
+			fmt.Sprintf("%s=%d", isolationHelperUIDEnv, shellUID),
+		)
+	}
 
 	// Set working directory
 	cmd.Dir = homeDir
@@ -74,6 +108,12 @@ func New(shell string, shellUID uint32) (*PTY, error) {
 			},
 		}
 		log.Printf("PTY: Starting shell as UID %d (home: %s)", shellUID, homeDir)
+
+		if namespaced {
+			cmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWPID | syscall.CLONE_NEWNS |
+				syscall.CLONE_NEWUTS | syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC
+			log.Printf("PTY: Starting shell with namespace isolation (PID/mount/UTS/net/IPC)")
+		}
 	} else {
 		log.Printf("PTY: Starting shell as current user (not root, cannot setuid)")
 	}
@@ -84,6 +124,14 @@ func New(shell string, shellUID uint32) (*PTY, error) {
 		return nil, fmt.Errorf("failed to start PTY: %w", err)
 	}
 
+	if namespaced {
+		// bindMountOverlayRoot happens inside the isolation helper, in the
+		// shell's own mount namespace - see RunIsolationHelper.
+		if err := confineToCgroup(cmd.Process.Pid, shellUID, isolation); err != nil {
+			log.Printf("Warning: Could not confine shell to cgroup: %v", err)
+		}
+	}
+
 	p := &PTY{
 		file:          ptmx,
 		cmd:           cmd,
@@ -172,6 +220,14 @@ func (p *PTY) AddWriter(w io.Writer) {
 	p.writers = append(p.writers, w)
 }
 
+// OnResize registers a hook that is called with the new dimensions every
+// time Resize succeeds (e.g. so a cast writer can emit a resize event).
+func (p *PTY) OnResize(hook func(cols, rows uint16)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resizeHooks = append(p.resizeHooks, hook)
+}
+
 // Resize resizes the PTY to the specified dimensions
 func (p *PTY) Resize(cols, rows uint16) error {
 	p.mu.Lock()
@@ -202,6 +258,10 @@ func (p *PTY) Resize(cols, rows uint16) error {
 		return fmt.Errorf("failed to resize PTY: %v", errno)
 	}
 
+	for _, hook := range p.resizeHooks {
+		hook(cols, rows)
+	}
+
 	return nil
 }
 
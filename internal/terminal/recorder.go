@@ -2,6 +2,7 @@ package terminal
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -28,6 +29,17 @@ type Recorder struct {
 	websocketWriter  *bufio.Writer
 	eventsWriter     *bufio.Writer
 
+	// sliceLog mirrors every event recorded below into a unified,
+	// hash-chained audit slice log (see slicelog.go), in parallel with
+	// the five per-purpose files above rather than instead of them.
+	sliceLog *SliceLog
+
+	// sinkPumps fan every recorded event out to the configured
+	// RecorderSinks (see sink.go), so a remote reviewer can tail a
+	// session live and artifacts can reach storage other than the local
+	// session directory.
+	sinkPumps []*sinkPump
+
 	// Timing tracking
 	lastOutputTime time.Time
 
@@ -39,90 +51,193 @@ type Recorder struct {
 	closed bool
 }
 
-// NewRecorder creates a new session recorder
-func NewRecorder(sessionDir string, flushInterval time.Duration) (*Recorder, error) {
+// NewRecorder creates a new session recorder. sinks, if non-empty,
+// receive a live copy of every recorded event in addition to the local
+// log files below; each sink gets its own bounded queue and retry loop
+// (see sink.go) so a slow or unreachable sink can't block recording.
+func NewRecorder(sessionDir, sessionID string, flushInterval time.Duration, sinks []RecorderSink, sinkBufferSize int) (*Recorder, error) {
 	r := &Recorder{
 		sessionDir: sessionDir,
 		startTime:  time.Now(),
 		flushDone:  make(chan struct{}),
 	}
 
+	for _, sink := range sinks {
+		r.sinkPumps = append(r.sinkPumps, newSinkPump(sink, sinkBufferSize))
+	}
+
+	sliceLog, err := NewSliceLog(sessionDir, sessionID, flushInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slice log: %w", err)
+	}
+	r.sliceLog = sliceLog
+
 	// Open all log files with write-only permissions during recording
-	// Files will be made read-only on Close() to prevent tampering
+	// (truncating any from a prior run of this same session directory).
+	// Files will be made read-only on Close() to prevent tampering.
+	if err := r.openLogFiles(os.O_CREATE | os.O_WRONLY | os.O_TRUNC); err != nil {
+		r.sliceLog.Close()
+		return nil, err
+	}
+
+	r.lastOutputTime = r.startTime
+
+	// Start periodic flush
+	r.flushTicker = time.NewTicker(flushInterval)
+	go r.flushLoop()
+
+	log.Printf("Recorder: Started session recording in %s", sessionDir)
+	return r, nil
+}
+
+// openLogFiles opens the five per-purpose log files under r.sessionDir
+// with the given os.OpenFile flag (O_TRUNC for a fresh session,
+// O_APPEND to resume one) and wires up their buffered writers, cleaning
+// up whatever it already opened if a later file fails.
+func (r *Recorder) openLogFiles(flag int) error {
 	var err error
 
-	r.keystrokesFile, err = os.OpenFile(
-		fmt.Sprintf("%s/keystrokes.log", sessionDir),
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		0600, // Owner read/write during recording
-	)
+	r.keystrokesFile, err = os.OpenFile(fmt.Sprintf("%s/keystrokes.log", r.sessionDir), flag, 0600)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create keystrokes.log: %w", err)
+		return fmt.Errorf("failed to open keystrokes.log: %w", err)
 	}
 
-	r.terminalFile, err = os.OpenFile(
-		fmt.Sprintf("%s/terminal.log", sessionDir),
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		0600,
-	)
+	r.terminalFile, err = os.OpenFile(fmt.Sprintf("%s/terminal.log", r.sessionDir), flag, 0600)
 	if err != nil {
 		r.keystrokesFile.Close()
-		return nil, fmt.Errorf("failed to create terminal.log: %w", err)
+		return fmt.Errorf("failed to open terminal.log: %w", err)
 	}
 
-	r.timingFile, err = os.OpenFile(
-		fmt.Sprintf("%s/timing.log", sessionDir),
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		0600,
-	)
+	r.timingFile, err = os.OpenFile(fmt.Sprintf("%s/timing.log", r.sessionDir), flag, 0600)
 	if err != nil {
 		r.keystrokesFile.Close()
 		r.terminalFile.Close()
-		return nil, fmt.Errorf("failed to create timing.log: %w", err)
+		return fmt.Errorf("failed to open timing.log: %w", err)
 	}
 
-	r.websocketFile, err = os.OpenFile(
-		fmt.Sprintf("%s/websocket.log", sessionDir),
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		0600,
-	)
+	r.websocketFile, err = os.OpenFile(fmt.Sprintf("%s/websocket.log", r.sessionDir), flag, 0600)
 	if err != nil {
 		r.keystrokesFile.Close()
 		r.terminalFile.Close()
 		r.timingFile.Close()
-		return nil, fmt.Errorf("failed to create websocket.log: %w", err)
+		return fmt.Errorf("failed to open websocket.log: %w", err)
 	}
 
-	r.eventsFile, err = os.OpenFile(
-		fmt.Sprintf("%s/events.log", sessionDir),
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		0600,
-	)
+	r.eventsFile, err = os.OpenFile(fmt.Sprintf("%s/events.log", r.sessionDir), flag, 0600)
 	if err != nil {
 		r.keystrokesFile.Close()
 		r.terminalFile.Close()
 		r.timingFile.Close()
 		r.websocketFile.Close()
-		return nil, fmt.Errorf("failed to create events.log: %w", err)
+		return fmt.Errorf("failed to open events.log: %w", err)
 	}
 
-	// Create buffered writers
 	r.keystrokesWriter = bufio.NewWriter(r.keystrokesFile)
 	r.terminalWriter = bufio.NewWriter(r.terminalFile)
 	r.timingWriter = bufio.NewWriter(r.timingFile)
 	r.websocketWriter = bufio.NewWriter(r.websocketFile)
 	r.eventsWriter = bufio.NewWriter(r.eventsFile)
 
-	r.lastOutputTime = r.startTime
+	return nil
+}
+
+// ResumeRecorder reopens a Recorder for sessionDir after a disconnect,
+// appending to the same five log files NewRecorder created instead of
+// truncating them, and restoring lastOutputTime from the
+// sessionDir/recorder.state sidecar (see recorderState) so
+// scriptreplay's per-write elapsed-seconds stay monotonic across the
+// gap instead of resetting to the resumed process's own start time.
+// lastSeqNo is the caller's last-known slice sequence number for this
+// session, recorded into the reconnect event for operator visibility;
+// it doesn't feed the new SliceLog's own numbering; each Recorder
+// instance - including a resumed one - writes into its own writer
+// subdirectory (see slicelog.go), and session.Manager.MergeSlices
+// orders across them by timestamp rather than by sequence number.
+func ResumeRecorder(sessionDir, sessionID string, lastSeqNo int64, flushInterval time.Duration, sinks []RecorderSink, sinkBufferSize int) (*Recorder, error) {
+	r := &Recorder{
+		sessionDir: sessionDir,
+		startTime:  time.Now(),
+		flushDone:  make(chan struct{}),
+	}
+
+	for _, sink := range sinks {
+		r.sinkPumps = append(r.sinkPumps, newSinkPump(sink, sinkBufferSize))
+	}
+
+	sliceLog, err := NewSliceLog(sessionDir, sessionID, flushInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slice log: %w", err)
+	}
+	r.sliceLog = sliceLog
+
+	gap, resumedFrom := readRecorderState(sessionDir)
+	if !resumedFrom.IsZero() {
+		r.lastOutputTime = resumedFrom
+	} else {
+		r.lastOutputTime = r.startTime
+	}
+
+	if err := r.openLogFiles(os.O_CREATE | os.O_WRONLY | os.O_APPEND); err != nil {
+		r.sliceLog.Close()
+		return nil, err
+	}
 
-	// Start periodic flush
 	r.flushTicker = time.NewTicker(flushInterval)
 	go r.flushLoop()
 
-	log.Printf("Recorder: Started session recording in %s", sessionDir)
+	reconnectData := fmt.Sprintf(`{"gap_seconds": %.3f, "last_seq_no": %d}`, gap.Seconds(), lastSeqNo)
+	if err := r.RecordEvent("reconnect", reconnectData); err != nil {
+		log.Printf("Recorder: failed to log reconnect event: %v", err)
+	}
+
+	log.Printf("Recorder: Resumed session recording in %s after a %v gap", sessionDir, gap)
 	return r, nil
 }
 
+// recorderState is the JSON shape of sessionDir/recorder.state, written
+// on every Flush so a future ResumeRecorder call can pick up scriptreplay
+// timing where this process left off, including after a crash that never
+// reached Close.
+type recorderState struct {
+	LastOutputTimeUnixNano int64 `json:"last_output_time_unix_nano"`
+}
+
+// writeRecorderState persists r.lastOutputTime to sessionDir/recorder.state.
+func (r *Recorder) writeRecorderState() error {
+	state := recorderState{LastOutputTimeUnixNano: r.lastOutputTime.UnixNano()}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recorder state: %w", err)
+	}
+
+	path := fmt.Sprintf("%s/recorder.state", r.sessionDir)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write recorder state: %w", err)
+	}
+	return nil
+}
+
+// readRecorderState reads sessionDir/recorder.state, if present, and
+// returns the gap since the last recorded output and the timestamp it
+// resumes from. A missing or unreadable sidecar (no earlier Recorder for
+// this directory ever flushed) yields a zero gap and a zero time, so the
+// caller falls back to its own start time.
+func readRecorderState(sessionDir string) (time.Duration, time.Time) {
+	data, err := os.ReadFile(fmt.Sprintf("%s/recorder.state", sessionDir))
+	if err != nil {
+		return 0, time.Time{}
+	}
+
+	var state recorderState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return 0, time.Time{}
+	}
+
+	lastOutputTime := time.Unix(0, state.LastOutputTimeUnixNano)
+	return time.Since(lastOutputTime), lastOutputTime
+}
+
 // RecordInput records keystroke input with timestamp
 func (r *Recorder) RecordInput(data []byte) error {
 	r.mu.Lock()
@@ -136,7 +251,15 @@ func (r *Recorder) RecordInput(data []byte) error {
 
 	// Format: timestamp_ms data_as_hex_or_printable
 	_, err := fmt.Fprintf(r.keystrokesWriter, "%d %q\n", timestamp, string(data))
-	return err
+	if err != nil {
+		return err
+	}
+
+	if err := r.sliceLog.RecordEvent("keystroke", data); err != nil {
+		return err
+	}
+	r.fanOut("keystroke", data)
+	return nil
 }
 
 // RecordOutput records terminal output in script/scriptreplay format
@@ -160,8 +283,15 @@ func (r *Recorder) RecordOutput(data []byte) error {
 	}
 
 	// Write terminal output
-	_, err = r.terminalWriter.Write(data)
-	return err
+	if _, err = r.terminalWriter.Write(data); err != nil {
+		return err
+	}
+
+	if err := r.sliceLog.RecordEvent("output", data); err != nil {
+		return err
+	}
+	r.fanOut("output", data)
+	return nil
 }
 
 // RecordWebSocketMessage records WebSocket message
@@ -183,7 +313,16 @@ func (r *Recorder) RecordWebSocketMessage(direction string, messageType string,
 
 	_, err := fmt.Fprintf(r.websocketWriter, "%d %s %s %d %q\n",
 		timestamp, direction, messageType, len(data), dataSample)
-	return err
+	if err != nil {
+		return err
+	}
+
+	sinkType := fmt.Sprintf("websocket_%s", direction)
+	if err := r.sliceLog.RecordEvent(sinkType, data); err != nil {
+		return err
+	}
+	r.fanOut(sinkType, data)
+	return nil
 }
 
 // RecordEvent records anti-cheat or session events
@@ -199,7 +338,27 @@ func (r *Recorder) RecordEvent(eventType string, data string) error {
 
 	// Format: timestamp event_type data
 	_, err := fmt.Fprintf(r.eventsWriter, "%d %s %s\n", timestamp, eventType, data)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if err := r.sliceLog.RecordEvent(eventType, []byte(data)); err != nil {
+		return err
+	}
+	r.fanOut(eventType, []byte(data))
+	return nil
+}
+
+// fanOut enqueues event on every configured sink's pump, without
+// blocking the caller on a slow or unreachable sink.
+func (r *Recorder) fanOut(eventType string, data []byte) {
+	if len(r.sinkPumps) == 0 {
+		return
+	}
+	event := SinkEvent{TSNs: time.Now().UnixNano(), Type: eventType, Payload: data}
+	for _, pump := range r.sinkPumps {
+		pump.enqueue(event)
+	}
 }
 
 // flushLoop periodically flushes all buffers
@@ -223,6 +382,13 @@ func (r *Recorder) Flush() error {
 		return nil
 	}
 
+	return r.flushLocked()
+}
+
+// flushLocked does the actual buffered-writer flush; callers must already
+// hold r.mu. It exists so Close can flush while closed is still false,
+// since Flush itself refuses to do anything once closed is set.
+func (r *Recorder) flushLocked() error {
 	var errs []error
 
 	if err := r.keystrokesWriter.Flush(); err != nil {
@@ -248,6 +414,10 @@ func (r *Recorder) Flush() error {
 	r.websocketFile.Sync()
 	r.eventsFile.Sync()
 
+	if err := r.writeRecorderState(); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("flush errors: %v", errs)
 	}
@@ -262,24 +432,32 @@ func (r *Recorder) Close() error {
 		r.mu.Unlock()
 		return nil
 	}
+	log.Println("Recorder: Closing and flushing all logs...")
+
+	// Final flush, before marking closed - Flush is a no-op once closed
+	// is true.
+	r.flushLocked()
 	r.closed = true
 	r.mu.Unlock()
 
-	log.Println("Recorder: Closing and flushing all logs...")
-
 	// Stop flush loop
 	r.flushTicker.Stop()
 	close(r.flushDone)
 
-	// Final flush
-	r.Flush()
-
 	// Close all files
 	r.keystrokesFile.Close()
 	r.terminalFile.Close()
 	r.timingFile.Close()
 	r.websocketFile.Close()
 	r.eventsFile.Close()
+	if err := r.sliceLog.Close(); err != nil {
+		log.Printf("Recorder: failed to close slice log: %v", err)
+	}
+	for _, pump := range r.sinkPumps {
+		if err := pump.close(); err != nil {
+			log.Printf("Recorder: failed to close sink: %v", err)
+		}
+	}
 
 	// Make log files read-only to prevent tampering
 	// After session ends, files become 0400 (owner read-only)
@@ -301,33 +479,10 @@ func (r *Recorder) Close() error {
 	return nil
 }
 
-// ExtractCommands extracts shell commands from terminal log (basic implementation)
-func ExtractCommands(sessionDir string) error {
-	// This is a simple implementation - can be enhanced later
-	terminalLogPath := fmt.Sprintf("%s/terminal.log", sessionDir)
-	commandsLogPath := fmt.Sprintf("%s/commands.log", sessionDir)
-
-	terminalData, err := os.ReadFile(terminalLogPath)
-	if err != nil {
-		return fmt.Errorf("failed to read terminal.log: %w", err)
-	}
-
-	commandsFile, err := os.Create(commandsLogPath)
-	if err != nil {
-		return fmt.Errorf("failed to create commands.log: %w", err)
-	}
-	defer commandsFile.Close()
-
-	// Simple extraction: look for common shell prompts and extract what follows
-	// This is a placeholder - real implementation would parse ANSI codes properly
-	_, err = commandsFile.Write([]byte(fmt.Sprintf("# Commands extracted from session\n# Terminal log size: %d bytes\n\n", len(terminalData))))
-	if err != nil {
-		return err
-	}
-
-	// TODO: Implement proper command extraction with ANSI parsing
-	commandsFile.WriteString("# Command extraction not yet implemented\n")
-	commandsFile.WriteString("# Use 'scriptreplay' to view the full session\n")
-
-	return nil
+// RootHash returns this recorder's slice log chain root, for persisting
+// into metadata.json alongside the per-file SHA-256 hashes.
+func (r *Recorder) RootHash() string {
+	return r.sliceLog.RootHash()
 }
+
+// ExtractCommands is implemented in commands.go.
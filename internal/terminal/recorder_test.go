@@ -0,0 +1,101 @@
+package terminal
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestResumeRecorderAcrossRestart kills a Recorder mid-session and
+// resumes it with ResumeRecorder, then checks that terminal.log and
+// timing.log read back as a single, gap-free, non-duplicated replay
+// across both halves of the session.
+func TestResumeRecorderAcrossRestart(t *testing.T) {
+	sessionDir := t.TempDir()
+	sessionID := "test-session"
+
+	r1, err := NewRecorder(sessionDir, sessionID, time.Hour, nil, 0)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	firstHalf := []string{"first line\n", "second line\n"}
+	for _, line := range firstHalf {
+		if err := r1.RecordOutput([]byte(line)); err != nil {
+			t.Fatalf("RecordOutput: %v", err)
+		}
+	}
+
+	if err := r1.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	// Simulate a crash: close the underlying files directly, bypassing
+	// Recorder.Close (which would make them read-only and stop the
+	// flush loop cleanly) - ResumeRecorder must cope with an unclean
+	// prior exit, not just a graceful one.
+	r1.keystrokesFile.Close()
+	r1.terminalFile.Close()
+	r1.timingFile.Close()
+	r1.websocketFile.Close()
+	r1.eventsFile.Close()
+	r1.flushTicker.Stop()
+
+	r2, err := ResumeRecorder(sessionDir, sessionID, 0, time.Hour, nil, 0)
+	if err != nil {
+		t.Fatalf("ResumeRecorder: %v", err)
+	}
+
+	secondHalf := []string{"third line\n", "fourth line\n"}
+	for _, line := range secondHalf {
+		if err := r2.RecordOutput([]byte(line)); err != nil {
+			t.Fatalf("RecordOutput after resume: %v", err)
+		}
+	}
+
+	if err := r2.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	terminalData, err := os.ReadFile(filepath.Join(sessionDir, "terminal.log"))
+	if err != nil {
+		t.Fatalf("reading terminal.log: %v", err)
+	}
+
+	want := strings.Join(append(append([]string{}, firstHalf...), secondHalf...), "")
+	if string(terminalData) != want {
+		t.Fatalf("terminal.log = %q, want %q (append lost or duplicated a frame)", terminalData, want)
+	}
+
+	timingFile, err := os.Open(filepath.Join(sessionDir, "timing.log"))
+	if err != nil {
+		t.Fatalf("opening timing.log: %v", err)
+	}
+	defer timingFile.Close()
+
+	lineCount := 0
+	scanner := bufio.NewScanner(timingFile)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			t.Fatalf("malformed timing.log line: %q", scanner.Text())
+		}
+		lineCount++
+	}
+	if lineCount != len(firstHalf)+len(secondHalf) {
+		t.Fatalf("timing.log has %d lines, want %d (one per RecordOutput call across both halves)",
+			lineCount, len(firstHalf)+len(secondHalf))
+	}
+
+	reconnectEventPath := filepath.Join(sessionDir, "events.log")
+	eventsData, err := os.ReadFile(reconnectEventPath)
+	if err != nil {
+		t.Fatalf("reading events.log: %v", err)
+	}
+	if !strings.Contains(string(eventsData), "reconnect") {
+		t.Fatalf("events.log missing reconnect event: %q", eventsData)
+	}
+}
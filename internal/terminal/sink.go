@@ -0,0 +1,141 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// SinkEvent is one recorded event fanned out to every configured
+// RecorderSink. Sinks receive this typed event rather than a raw byte
+// stream, so a remote reviewer tailing a session can tell a keystroke
+// from PTY output without re-parsing the wire format.
+type SinkEvent struct {
+	TSNs    int64  `json:"ts_ns"`
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+}
+
+// RecorderSink receives a live copy of every event Recorder records, so
+// session artifacts can reach somewhere other than the local session
+// directory - useful when the local filesystem is ephemeral (a
+// container) rather than durable.
+type RecorderSink interface {
+	// Send delivers one event. A returned error causes the caller's
+	// sinkPump to retry with backoff; Send should be safe to call again
+	// with the same event.
+	Send(event SinkEvent) error
+
+	// Close flushes and releases any resources held by the sink.
+	Close() error
+}
+
+const (
+	sinkMaxRetries     = 5
+	sinkRetryBaseDelay = 200 * time.Millisecond
+)
+
+// sinkPump owns one RecorderSink's bounded event queue and retry loop, so
+// a slow or unreachable sink applies back-pressure only to itself rather
+// than blocking the recorder or other sinks.
+type sinkPump struct {
+	sink   RecorderSink
+	events chan SinkEvent
+	done   chan struct{}
+}
+
+// newSinkPump starts a goroutine draining events into sink, retrying each
+// with exponential backoff before giving up on it.
+func newSinkPump(sink RecorderSink, bufferSize int) *sinkPump {
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+
+	p := &sinkPump{
+		sink:   sink,
+		events: make(chan SinkEvent, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// enqueue offers event to the pump without blocking the caller; a full
+// queue (the sink can't keep up) drops the event rather than stalling the
+// recorder, matching the non-blocking back-pressure pattern used for
+// WSHandler.finishSignal.
+func (p *sinkPump) enqueue(event SinkEvent) {
+	select {
+	case p.events <- event:
+	default:
+		log.Printf("RecorderSink: queue full, dropping %s event", event.Type)
+	}
+}
+
+func (p *sinkPump) run() {
+	for {
+		select {
+		case event := <-p.events:
+			p.sendWithRetry(event)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func (p *sinkPump) sendWithRetry(event SinkEvent) {
+	delay := sinkRetryBaseDelay
+	for attempt := 1; attempt <= sinkMaxRetries; attempt++ {
+		err := p.sink.Send(event)
+		if err == nil {
+			return
+		}
+		if attempt == sinkMaxRetries {
+			log.Printf("RecorderSink: giving up on %s event after %d attempts: %v", event.Type, attempt, err)
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (p *sinkPump) close() error {
+	close(p.done)
+	return p.sink.Close()
+}
+
+// DiskSink appends every event as a line of JSON to a local file,
+// independent of the five per-purpose log files Recorder already
+// maintains - the local-disk RecorderSink, kept for parity with the
+// remote sinks rather than as the primary recording path.
+type DiskSink struct {
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewDiskSink creates (or truncates) path and returns a RecorderSink that
+// appends each event to it as JSON Lines.
+func NewDiskSink(path string) (*DiskSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	return &DiskSink{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Send writes event as a single JSON line.
+func (s *DiskSink) Send(event SinkEvent) error {
+	return s.enc.Encode(event)
+}
+
+// Close syncs and closes the underlying file.
+func (s *DiskSink) Close() error {
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,99 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// WebhookSink POSTs each event as JSON to a configured URL, letting a
+// remote reviewer hook up an arbitrary HTTP endpoint (a dashboard, a
+// queue ingester) without echobox knowing anything about it.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a RecorderSink that POSTs events to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Send POSTs event's JSON encoding to the webhook URL.
+func (s *WebhookSink) Send(event SinkEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event for webhook: %w", err)
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to POST event to %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// Close is a no-op; WebhookSink holds no resources between requests.
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// syslogFacility is the RFC 5424 facility echobox events are logged
+// under - local0, the conventional facility for application-defined use.
+const syslogFacility = 16
+
+// SyslogSink writes each event as an RFC 5424 syslog message to a
+// network syslog server, so session activity can be tailed through
+// existing log-aggregation pipelines rather than a bespoke viewer.
+type SyslogSink struct {
+	conn     net.Conn
+	hostname string
+}
+
+// NewSyslogSink dials addr (host:port) over network (e.g. "tcp", "udp")
+// and returns a RecorderSink that writes RFC 5424 messages to it.
+func NewSyslogSink(network, addr string) (*SyslogSink, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial syslog server %s: %w", addr, err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "echobox"
+	}
+
+	return &SyslogSink{conn: conn, hostname: hostname}, nil
+}
+
+// Send writes event as one RFC 5424 message:
+// "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+func (s *SyslogSink) Send(event SinkEvent) error {
+	pri := syslogFacility*8 + 6 // severity 6 = informational
+	ts := time.Unix(0, event.TSNs).UTC().Format(time.RFC3339Nano)
+
+	msg := fmt.Sprintf("<%d>1 %s %s echobox %d %s - %s\n",
+		pri, ts, s.hostname, os.Getpid(), event.Type, event.Payload)
+
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("failed to write syslog message: %w", err)
+	}
+	return nil
+}
+
+// Close releases the syslog connection.
+func (s *SyslogSink) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,148 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3MinPartSize is S3's minimum multipart upload part size (except for
+// the final part); events are buffered until they reach this size before
+// a part is actually uploaded.
+const s3MinPartSize = 5 * 1024 * 1024
+
+// S3Sink streams events to an S3-compatible bucket as a single object via
+// a multipart upload, so a long session doesn't have to be buffered
+// entirely in memory or uploaded as one huge PutObject at the end.
+// UploadID is exposed so the caller can persist it into metadata.json -
+// if the process crashes mid-session, a future tool can complete or
+// abort the upload rather than leaving it billing indefinitely.
+type S3Sink struct {
+	client   *s3.Client
+	bucket   string
+	key      string
+	uploadID string
+
+	mu         sync.Mutex
+	buf        bytes.Buffer
+	partNumber int32
+	parts      []types.CompletedPart
+}
+
+// NewS3Sink starts a multipart upload for s3://bucket/key.
+func NewS3Sink(ctx context.Context, client *s3.Client, bucket, key string) (*S3Sink, error) {
+	out, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start multipart upload for s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return &S3Sink{
+		client:   client,
+		bucket:   bucket,
+		key:      key,
+		uploadID: aws.ToString(out.UploadId),
+	}, nil
+}
+
+// UploadID returns the multipart upload ID, for persisting into
+// metadata.json so an interrupted upload can be resumed or aborted.
+func (s *S3Sink) UploadID() string {
+	return s.uploadID
+}
+
+// Send appends event's JSON encoding to the current part buffer,
+// uploading a part once it reaches s3MinPartSize.
+func (s *S3Sink) Send(event SinkEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event for s3 upload: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prevLen := s.buf.Len()
+	s.buf.Write(data)
+	s.buf.WriteByte('\n')
+
+	if s.buf.Len() < s3MinPartSize {
+		return nil
+	}
+	if err := s.uploadPartLocked(); err != nil {
+		// Unbuffer the event so a retried Send doesn't write it twice.
+		s.buf.Truncate(prevLen)
+		return err
+	}
+	return nil
+}
+
+func (s *S3Sink) uploadPartLocked() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+
+	s.partNumber++
+	body := bytes.NewReader(s.buf.Bytes())
+
+	out, err := s.client.UploadPart(context.Background(), &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(s.key),
+		UploadId:   aws.String(s.uploadID),
+		PartNumber: aws.Int32(s.partNumber),
+		Body:       body,
+	})
+	if err != nil {
+		s.partNumber-- // retry will reuse this part number
+		return fmt.Errorf("failed to upload part %d of s3://%s/%s: %w", s.partNumber+1, s.bucket, s.key, err)
+	}
+
+	s.parts = append(s.parts, types.CompletedPart{
+		ETag:       out.ETag,
+		PartNumber: aws.Int32(s.partNumber),
+	})
+	s.buf.Reset()
+	return nil
+}
+
+// Close uploads any remaining buffered events as the final part (which,
+// unlike earlier parts, may be smaller than s3MinPartSize) and completes
+// the multipart upload.
+func (s *S3Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.uploadPartLocked(); err != nil {
+		return err
+	}
+
+	if len(s.parts) == 0 {
+		_, err := s.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(s.key),
+			UploadId: aws.String(s.uploadID),
+		})
+		return err
+	}
+
+	_, err := s.client.CompleteMultipartUpload(context.Background(), &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(s.key),
+		UploadId: aws.String(s.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: s.parts,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload for s3://%s/%s: %w", s.bucket, s.key, err)
+	}
+	return nil
+}
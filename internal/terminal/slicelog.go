@@ -0,0 +1,231 @@
+package terminal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sliceFlushBytes is the batch size threshold: a slice is flushed once its
+// buffered events' type and payload bytes add up to this many,
+// independent of FlushInterval.
+const sliceFlushBytes = 64 * 1024
+
+// SliceEvent is one event folded into a Slice - a keystroke, a chunk of
+// PTY output, a WebSocket message, or an anti-cheat event, all unified
+// into the same append-only stream.
+type SliceEvent struct {
+	TSNs    int64  `json:"ts_ns"`
+	Type    string `json:"type"`
+	Payload string `json:"payload"`
+}
+
+// Slice is one forward-secure, hash-chained batch of SliceEvents. Each
+// slice's hash covers the previous slice's hash plus this slice's own
+// canonical event payload, so any byte flip in any slice - or any slice
+// dropped or reordered - breaks the chain from that point forward.
+type Slice struct {
+	SeqNo            int64        `json:"seq_no"`
+	SessionID        string       `json:"session_id"`
+	StartOffsetBytes int64        `json:"start_offset_bytes"`
+	Events           []SliceEvent `json:"events"`
+	PrevHash         string       `json:"prev_hash"`
+	Hash             string       `json:"hash"`
+}
+
+// SliceLog is a single writer's append-only, tamper-evident event log.
+// Each SliceLog instance writes into its own subdirectory
+// (sessionDir/slices/<hostname>-<pid>/, with a -<n> suffix on the n-th
+// later instance in the same process) so multiple writers for the same
+// session - e.g. a reconnect worker alongside the original process -
+// never contend over the same file; session.Manager.MergeSlices produces
+// a single ordered stream across every writer's subdirectory.
+type SliceLog struct {
+	sessionID string
+	dir       string
+
+	mu          sync.Mutex
+	seqNo       int64
+	offset      int64
+	prevHash    string
+	buffer      []SliceEvent
+	bufferBytes int
+	closed      bool
+
+	flushTicker *time.Ticker
+	flushDone   chan struct{}
+}
+
+// sliceLogInstances counts the SliceLogs this process has created.
+var sliceLogInstances int64
+
+// NewSliceLog creates sessionDir/slices/<hostname>-<pid>/ (or
+// <hostname>-<pid>-<n> for a later instance in the same process) and
+// starts the periodic flush loop.
+func NewSliceLog(sessionDir, sessionID string, flushInterval time.Duration) (*SliceLog, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown-host"
+	}
+
+	name := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	if n := atomic.AddInt64(&sliceLogInstances, 1) - 1; n > 0 {
+		name = fmt.Sprintf("%s-%d", name, n)
+	}
+	dir := filepath.Join(sessionDir, "slices", name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create slice log directory: %w", err)
+	}
+
+	l := &SliceLog{
+		sessionID: sessionID,
+		dir:       dir,
+		flushDone: make(chan struct{}),
+	}
+
+	l.flushTicker = time.NewTicker(flushInterval)
+	go l.flushLoop()
+
+	return l, nil
+}
+
+// RecordEvent appends one event to the current batch, flushing
+// immediately if the batch has grown past sliceFlushBytes.
+func (l *SliceLog) RecordEvent(eventType string, payload []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return fmt.Errorf("slice log is closed")
+	}
+
+	event := SliceEvent{TSNs: time.Now().UnixNano(), Type: eventType, Payload: string(payload)}
+	l.buffer = append(l.buffer, event)
+	l.bufferBytes += len(eventType) + len(payload)
+
+	if l.bufferBytes >= sliceFlushBytes {
+		return l.flushLocked()
+	}
+	return nil
+}
+
+// Flush seals whatever events are currently buffered into a new slice,
+// regardless of size.
+func (l *SliceLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flushLocked()
+}
+
+func (l *SliceLog) flushLocked() error {
+	if len(l.buffer) == 0 {
+		return nil
+	}
+
+	canonical, err := json.Marshal(l.buffer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slice events: %w", err)
+	}
+
+	sum := sha256.Sum256(append([]byte(l.prevHash), canonical...))
+	hash := hex.EncodeToString(sum[:])
+
+	slice := Slice{
+		SeqNo:            l.seqNo,
+		SessionID:        l.sessionID,
+		StartOffsetBytes: l.offset,
+		Events:           l.buffer,
+		PrevHash:         l.prevHash,
+		Hash:             hash,
+	}
+
+	data, err := json.MarshalIndent(slice, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal slice: %w", err)
+	}
+
+	finalPath := filepath.Join(l.dir, fmt.Sprintf("%08d.json", l.seqNo))
+	if err := writeFileAtomic(finalPath, data); err != nil {
+		return err
+	}
+
+	l.prevHash = hash
+	l.seqNo++
+	l.offset += int64(len(canonical))
+	l.buffer = nil
+	l.bufferBytes = 0
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory as
+// path, fsyncs it, then renames it into place - so a crash mid-write
+// never leaves a partially-written slice for a reader to trip over.
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0400)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to fsync %s: %w", tmpPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", tmpPath, path, err)
+	}
+
+	return nil
+}
+
+// RootHash returns the hash of the most recently sealed slice - the
+// chain root a reviewer can use to detect any post-hoc byte flip in this
+// writer's slices.
+func (l *SliceLog) RootHash() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.prevHash
+}
+
+func (l *SliceLog) flushLoop() {
+	for {
+		select {
+		case <-l.flushTicker.C:
+			l.Flush()
+		case <-l.flushDone:
+			return
+		}
+	}
+}
+
+// Close flushes any buffered events and stops the flush loop.
+func (l *SliceLog) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	l.flushTicker.Stop()
+	close(l.flushDone)
+
+	return l.Flush()
+}
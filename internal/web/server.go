@@ -2,29 +2,43 @@ package web
 
 import (
 	"context"
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	kcp "github.com/xtaci/kcp-go/v5"
+	"golang.org/x/crypto/pbkdf2"
+
 	"github.com/akonyukov/echobox/internal/config"
+	"github.com/akonyukov/echobox/internal/terminal"
 )
 
+// kcpSalt is a fixed salt for deriving the KCP block cipher key from the
+// configured passphrase; the passphrase itself is the actual secret.
+const kcpSalt = "echobox-kcp-salt"
+
 // Server represents the HTTP server
 type Server struct {
-	config     *config.Config
-	httpServer *http.Server
-	wsHandler  *WSHandler
+	config        *config.Config
+	httpServer    *http.Server
+	wsHandler     *WSHandler
+	sessionID     string
+	onChaosUpdate func(terminal.ChaosConfig)
 }
 
 // New creates a new HTTP server
-func New(cfg *config.Config, wsHandler *WSHandler) *Server {
+func New(cfg *config.Config, wsHandler *WSHandler, sessionID string) *Server {
 	s := &Server{
 		config:    cfg,
 		wsHandler: wsHandler,
+		sessionID: sessionID,
 	}
 
 	mux := http.NewServeMux()
@@ -32,6 +46,16 @@ func New(cfg *config.Config, wsHandler *WSHandler) *Server {
 	// WebSocket endpoint
 	mux.HandleFunc("/ws", wsHandler.Handle)
 
+	// Read-only spectator endpoint: /watch/{sessionID}?token=...
+	mux.HandleFunc("/watch/", s.handleWatch)
+
+	// Read-only spectator endpoint over SSE, for clients that can't or
+	// shouldn't use WebSockets: /events/{sessionID}?token=...
+	mux.HandleFunc("/events/", s.handleEvents)
+
+	// Admin endpoint to inject link-degradation chaos mid-session
+	mux.HandleFunc("/admin/chaos", s.handleAdminChaos)
+
 	// Reconnection endpoint
 	mux.HandleFunc("/reconnect", s.handleReconnect)
 
@@ -52,15 +76,56 @@ func New(cfg *config.Config, wsHandler *WSHandler) *Server {
 	return s
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server over the configured transport. The
+// WebSocket upgrade path (wsHandler.Handle) is unchanged either way -
+// only the underlying net.Listener differs.
 func (s *Server) Start() error {
-	log.Printf("Starting server on port %d...", s.config.Port)
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	log.Printf("Starting server on port %d (transport=%s)...", s.config.Port, s.config.Transport)
+
+	listener, err := s.listen()
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server error: %w", err)
 	}
 	return nil
 }
 
+// listen opens the net.Listener for the configured transport. "kcp" uses
+// a reliable-UDP session with forward error correction so candidates on
+// lossy links don't suffer TCP head-of-line blocking that garbles
+// terminal echo; the reconnect-token flow in handleReconnect works
+// unchanged since it is layered entirely above the listener.
+func (s *Server) listen() (net.Listener, error) {
+	addr := fmt.Sprintf(":%d", s.config.Port)
+
+	if s.config.Transport != "kcp" {
+		return net.Listen("tcp", addr)
+	}
+
+	block, err := kcp.NewAESBlockCrypt(deriveKCPKey(s.config.KCPKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build KCP block cipher: %w", err)
+	}
+
+	// dataShards=10, parityShards=3: tolerates losing up to 3 of every
+	// 13 packets without a retransmit round-trip.
+	listener, err := kcp.ListenWithOptions(addr, block, 10, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on KCP %s: %w", addr, err)
+	}
+
+	return listener, nil
+}
+
+// deriveKCPKey derives a 32-byte AES-256 key from the configured
+// passphrase so operators can set a human-readable KCP_KEY env var.
+func deriveKCPKey(passphrase string) []byte {
+	return pbkdf2.Key([]byte(passphrase), []byte(kcpSalt), 4096, 32, sha1.New)
+}
+
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Println("Shutting down server...")
@@ -129,10 +194,10 @@ func (s *Server) handleReconnect(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	size := s.wsHandler.sessionState.GetTerminalSize()
-	buffer := s.wsHandler.sessionState.GetTerminalBuffer()
+	buffer := s.wsHandler.sessionState.GetReplayFrame()
 
 	response := map[string]interface{}{
-		"status": "ok",
+		"status":  "ok",
 		"message": "Reconnection allowed",
 		"terminal": map[string]interface{}{
 			"cols":   size.Cols,
@@ -145,11 +210,118 @@ func (s *Server) handleReconnect(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Reconnection approved for token: %s", token[:8]+"...")
 }
 
+// SetChaosRecorder registers a callback invoked with every accepted
+// chaos config, so the caller can persist it to session metadata (this
+// lets post-hoc anti-cheat analysis distinguish injected delays from
+// genuine candidate typing patterns).
+func (s *Server) SetChaosRecorder(fn func(terminal.ChaosConfig)) {
+	s.onChaosUpdate = fn
+}
+
+// maxChaosLatencyMS and maxChaosJitterMS bound the impairment /admin/chaos
+// can inject. Without a ceiling, a POST with an arbitrarily large
+// latency_ms stalls every PTY read/write for the operator connection for
+// that long - a trivial DoS against the interview session the server
+// exists to run.
+const (
+	maxChaosLatencyMS = 5000
+	maxChaosJitterMS  = 2000
+)
+
+// handleAdminChaos accepts a POST body describing link impairments to
+// inject into the live PTY stream, e.g.
+// {"latency_ms":250,"jitter_ms":50,"bw_bps":9600,"loss_pct":0.5}
+//
+// It shares authorizeUpgrade's origin/token check with the /ws, /watch,
+// and /events endpoints: this reaches the same PTY the candidate's shell
+// runs behind, so it needs the same protection against an unauthenticated
+// client on the listener reconfiguring the live session.
+func (s *Server) handleAdminChaos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if ok, reason := s.wsHandler.authorizeUpgrade(r, false); !ok {
+		s.wsHandler.logAuthRejection(r, reason)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var cfg terminal.ChaosConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid chaos config: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateChaosConfig(cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.wsHandler.SetChaosConfig(cfg)
+	if s.onChaosUpdate != nil {
+		s.onChaosUpdate(cfg)
+	}
+
+	log.Printf("Admin: chaos config updated: %+v", cfg)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(cfg)
+}
+
+// validateChaosConfig rejects a ChaosConfig whose fields could stall the
+// PTY for an unreasonable duration or aren't meaningful values, rather
+// than silently clamping them and leaving the caller unsure what was
+// actually applied.
+func validateChaosConfig(cfg terminal.ChaosConfig) error {
+	if cfg.LatencyMS < 0 || cfg.LatencyMS > maxChaosLatencyMS {
+		return fmt.Errorf("latency_ms must be between 0 and %d", maxChaosLatencyMS)
+	}
+	if cfg.JitterMS < 0 || cfg.JitterMS > maxChaosJitterMS {
+		return fmt.Errorf("jitter_ms must be between 0 and %d", maxChaosJitterMS)
+	}
+	if cfg.BWBps < 0 {
+		return fmt.Errorf("bw_bps must not be negative")
+	}
+	if cfg.LossPct < 0 || cfg.LossPct > 100 {
+		return fmt.Errorf("loss_pct must be between 0 and 100")
+	}
+	return nil
+}
+
+// handleWatch upgrades a spectator to a read-only WebSocket stream of the
+// live session, scoped to a particular session ID for future multi-session
+// deployments.
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	sessionID := strings.TrimPrefix(r.URL.Path, "/watch/")
+	if sessionID == "" || sessionID != s.sessionID {
+		http.Error(w, "Unknown session", http.StatusNotFound)
+		return
+	}
+
+	s.wsHandler.HandleWatch(w, r)
+}
+
+// handleEvents streams the live session as Server-Sent Events, the
+// WebSocket-free counterpart to handleWatch, scoped to the same session ID.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	sessionID := strings.TrimPrefix(r.URL.Path, "/events/")
+	if sessionID == "" || sessionID != s.sessionID {
+		http.Error(w, "Unknown session", http.StatusNotFound)
+		return
+	}
+
+	s.wsHandler.HandleEvents(w, r)
+}
+
 // handleHealth handles health check requests
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `{"status":"ok","candidate":"%s"}`, s.config.CandidateName)
+	fmt.Fprintf(w, `{"status":"ok","candidate":"%s","viewers":%d}`,
+		s.config.CandidateName, s.wsHandler.Hub().Count())
 }
 
 // handleStatic serves static files with correct Content-Type
@@ -185,20 +357,20 @@ func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
 // getContentType returns the correct Content-Type for file extensions
 func getContentType(ext string) string {
 	contentTypes := map[string]string{
-		".html": "text/html; charset=utf-8",
-		".css":  "text/css; charset=utf-8",
-		".js":   "application/javascript; charset=utf-8",
-		".json": "application/json; charset=utf-8",
-		".png":  "image/png",
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".gif":  "image/gif",
-		".svg":  "image/svg+xml",
-		".ico":  "image/x-icon",
-		".woff": "font/woff",
+		".html":  "text/html; charset=utf-8",
+		".css":   "text/css; charset=utf-8",
+		".js":    "application/javascript; charset=utf-8",
+		".json":  "application/json; charset=utf-8",
+		".png":   "image/png",
+		".jpg":   "image/jpeg",
+		".jpeg":  "image/jpeg",
+		".gif":   "image/gif",
+		".svg":   "image/svg+xml",
+		".ico":   "image/x-icon",
+		".woff":  "font/woff",
 		".woff2": "font/woff2",
-		".ttf":  "font/ttf",
-		".eot":  "application/vnd.ms-fontobject",
+		".ttf":   "font/ttf",
+		".eot":   "application/vnd.ms-fontobject",
 	}
 
 	if ct, ok := contentTypes[ext]; ok {
@@ -1,10 +1,16 @@
 package web
 
 import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,11 +21,15 @@ import (
 	"github.com/akonyukov/echobox/internal/terminal"
 )
 
+// Origin is validated in WSHandler.authorizeUpgrade, not here, so every
+// caller's configurable OriginPolicy/Authenticator actually runs instead
+// of this blanket allow.
 var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
+	ReadBufferSize:    1024,
+	WriteBufferSize:   1024,
+	EnableCompression: true,
 	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for now
+		return true
 	},
 }
 
@@ -35,27 +45,419 @@ type ResizeData struct {
 	Rows uint16 `json:"rows"`
 }
 
+// KeepaliveConfig tunes the ping/pong keepalive that Handle and HandleWatch
+// use to detect dead connections - NAT timeout, client crash, network loss
+// - that would otherwise go unnoticed until a write fails, leaving
+// SessionState.Disconnect() uncalled and the reconnect window never started.
+type KeepaliveConfig struct {
+	PingInterval   time.Duration // how often to ping an idle connection
+	PongTimeout    time.Duration // read deadline granted per received pong
+	MaxMessageSize int64         // gorilla SetReadLimit; 0 means unlimited
+}
+
+// DefaultKeepaliveConfig returns the keepalive tuning used unless a caller
+// needs something unusual: ping every 30s, allow 60s (twice the interval,
+// the standard gorilla/websocket margin) of silence before giving up, and
+// cap inbound messages at 1MiB.
+func DefaultKeepaliveConfig() KeepaliveConfig {
+	return KeepaliveConfig{
+		PingInterval:   30 * time.Second,
+		PongTimeout:    60 * time.Second,
+		MaxMessageSize: 1 << 20,
+	}
+}
+
+// CompressionConfig tunes RFC 7692 permessage-deflate for outbound frames.
+// Small frames (keystroke echoes) skip compression entirely, since deflate
+// overhead dominates at that size; everything at or above
+// MinMessageSizeBytes is compressed at Level.
+type CompressionConfig struct {
+	Level               int // compress/flate level: -2..9, -1 = DefaultCompression
+	MinMessageSizeBytes int // frames smaller than this are sent uncompressed
+}
+
+// DefaultCompressionConfig compresses any frame of 256 bytes or more at
+// flate.DefaultCompression, which is the sweet spot for terminal output
+// (escape sequences, whitespace, repeated log lines) without burning CPU
+// on every few-byte keystroke echo.
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Level:               flate.DefaultCompression,
+		MinMessageSizeBytes: 256,
+	}
+}
+
+// CompressionStats accumulates the observed effect of permessage-deflate
+// on one connection's outbound frames. BytesOut is an estimate (gorilla
+// doesn't expose the actual wire size), computed by compressing a copy of
+// each compressed frame at the same level purely for accounting.
+type CompressionStats struct {
+	FramesCompressed int64 `json:"frames_compressed"`
+	FramesSkipped    int64 `json:"frames_skipped"`
+	BytesIn          int64 `json:"bytes_in"`
+	BytesOutEstimate int64 `json:"bytes_out_estimate"`
+}
+
+// OriginPolicy validates an upgrade request's Origin header against an
+// allowlist of exact origins/hosts and wildcard-suffix patterns (e.g.
+// "*.example.com"). A nil/empty Origins list allows every origin,
+// matching the upgrader's previous unconditional CheckOrigin.
+type OriginPolicy struct {
+	Origins []string
+}
+
+// Allowed reports whether origin satisfies the policy.
+func (p OriginPolicy) Allowed(origin string) bool {
+	if len(p.Origins) == 0 {
+		return true
+	}
+	if origin == "" {
+		return false
+	}
+
+	host := origin
+	if u, err := url.Parse(origin); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	for _, allowed := range p.Origins {
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+			continue
+		}
+		if origin == allowed || host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator validates a bearer token presented on a WebSocket upgrade
+// request (see extractUpgradeToken). It's pluggable so a deployment can
+// swap in real JWT verification without changing WSHandler; a nil
+// Authenticator on WSHandler disables the check entirely.
+type Authenticator interface {
+	Authenticate(token string) bool
+}
+
+// ReconnectTokenAuthenticator ties WebSocket upgrade auth to the same
+// token SessionState already mints for its own reconnect flow, so that
+// flow becomes actually authenticated instead of a client-supplied hint.
+type ReconnectTokenAuthenticator struct {
+	sessionState *session.SessionState
+}
+
+// NewReconnectTokenAuthenticator returns an Authenticator backed by
+// sessionState's reconnect token.
+func NewReconnectTokenAuthenticator(sessionState *session.SessionState) *ReconnectTokenAuthenticator {
+	return &ReconnectTokenAuthenticator{sessionState: sessionState}
+}
+
+// Authenticate reports whether token matches the session's reconnect
+// token. An empty token never authenticates, even if the session has no
+// reconnect token configured yet.
+func (a *ReconnectTokenAuthenticator) Authenticate(token string) bool {
+	if token == "" || a.sessionState == nil {
+		return false
+	}
+	return token == a.sessionState.GetReconnectToken()
+}
+
 // WSHandler handles WebSocket connections
 type WSHandler struct {
-	pty          *terminal.PTY
-	recorder     *terminal.Recorder
-	detector     *anticheat.Detector
-	sessionState *session.SessionState
-	mu           sync.RWMutex
-	shutdown     chan struct{}
-	finishSignal chan struct{}
+	pty           *terminal.PTY
+	chaos         *terminal.Chaos
+	recorder      *terminal.Recorder
+	cast          *terminal.CastWriter
+	detector      *anticheat.Detector
+	sessionState  *session.SessionState
+	hub           *SessionHub
+	viewerToken   string
+	keepalive     KeepaliveConfig
+	mu            sync.RWMutex // guards compression/originPolicy/authenticator
+	compression   CompressionConfig
+	originPolicy  OriginPolicy
+	authenticator Authenticator
+	shutdown      chan struct{}
+	finishSignal  chan struct{}
+
+	// operatorMu/operatorConnected gate the single interactive operator
+	// slot on Handle: first WS connection wins it, everyone else gets a
+	// 409 until that connection's Handle call returns (shell exit,
+	// client disconnect, or server shutdown); after that a reconnect
+	// through the same /ws endpoint can claim it. Viewers never touch
+	// this - they go through HandleWatch.
+	operatorMu        sync.Mutex
+	operatorConnected bool
+}
+
+// SetOriginPolicy replaces the allowlist WSHandler validates every
+// upgrade's Origin header against. The zero value allows every origin.
+func (h *WSHandler) SetOriginPolicy(policy OriginPolicy) {
+	h.mu.Lock()
+	h.originPolicy = policy
+	h.mu.Unlock()
+}
+
+// SetAuthenticator installs a bearer-token check run on /ws upgrades and
+// /admin/chaos requests. On HandleWatch/HandleEvents a valid viewer token
+// takes its place (see authorizeUpgrade). Pass nil to disable the check
+// (the default).
+func (h *WSHandler) SetAuthenticator(auth Authenticator) {
+	h.mu.Lock()
+	h.authenticator = auth
+	h.mu.Unlock()
+}
+
+// authorizeUpgrade validates an upgrade request's Origin against the
+// configured OriginPolicy and, if an Authenticator is installed, its
+// bearer token. ok is false if either check fails; reason explains why,
+// for logAuthRejection.
+//
+// viewerTokenOK lets HandleWatch/HandleEvents satisfy the Authenticator
+// check with their own, separately-generated viewer token instead of the
+// Authenticator's token: the two secrets (reconnect token vs. viewer
+// token) are never equal, so without this a viewer could never pass both
+// checks at once. Handle passes false - the operator path has no viewer
+// token to substitute.
+func (h *WSHandler) authorizeUpgrade(r *http.Request, viewerTokenOK bool) (ok bool, reason string) {
+	h.mu.RLock()
+	policy := h.originPolicy
+	auth := h.authenticator
+	h.mu.RUnlock()
+
+	if !policy.Allowed(r.Header.Get("Origin")) {
+		return false, "origin not allowed"
+	}
+
+	if auth != nil && !viewerTokenOK && !auth.Authenticate(extractUpgradeToken(r)) {
+		return false, "invalid or missing token"
+	}
+
+	return true, ""
+}
+
+// extractUpgradeToken reads a bearer token from an upgrade request's
+// "token" query parameter, falling back to a "bearer.<token>"
+// Sec-WebSocket-Protocol entry for clients that can't set query params
+// (some browser WebSocket/EventSource wrappers only expose subprotocols).
+func extractUpgradeToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	for _, proto := range websocket.Subprotocols(r) {
+		if strings.HasPrefix(proto, "bearer.") {
+			return strings.TrimPrefix(proto, "bearer.")
+		}
+	}
+	return ""
+}
+
+// logAuthRejection records a request rejected by authorizeUpgrade as a
+// critical anti-cheat event, so brute-force origin/token probing shows up
+// in the audit log rather than just a line in the process log. Used for
+// both WebSocket/SSE upgrades and the plain HTTP admin endpoints that
+// share authorizeUpgrade's origin/token check.
+func (h *WSHandler) logAuthRejection(r *http.Request, reason string) {
+	log.Printf("Rejected request from %s (%s): %s", r.RemoteAddr, r.URL.Path, reason)
+
+	if h.detector == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"remote_addr": r.RemoteAddr,
+		"origin":      r.Header.Get("Origin"),
+		"path":        r.URL.Path,
+		"reason":      reason,
+	}
+	event := h.detector.GetLogger().LogCustomEvent(anticheat.SeverityCritical, "ws_upgrade_rejected",
+		fmt.Sprintf("WebSocket upgrade rejected: %s", reason), data)
+
+	if h.recorder != nil {
+		eventJSON, _ := json.Marshal(event)
+		if err := h.recorder.RecordEvent("anticheat_violation", string(eventJSON)); err != nil {
+			log.Printf("Failed to record upgrade rejection: %v", err)
+		}
+	}
+}
+
+// NewWSHandler creates a new WebSocket handler using DefaultKeepaliveConfig
+// and DefaultCompressionConfig. Use NewWSHandlerWithOptions to override
+// either.
+func NewWSHandler(pty *terminal.PTY, recorder *terminal.Recorder, cast *terminal.CastWriter, detector *anticheat.Detector, sessionState *session.SessionState, viewerToken string) *WSHandler {
+	return NewWSHandlerWithKeepalive(pty, recorder, cast, detector, sessionState, viewerToken, DefaultKeepaliveConfig())
+}
+
+// NewWSHandlerWithKeepalive creates a new WebSocket handler with an
+// explicit ping interval, pong timeout, and max message size, using
+// DefaultCompressionConfig for permessage-deflate tuning.
+func NewWSHandlerWithKeepalive(pty *terminal.PTY, recorder *terminal.Recorder, cast *terminal.CastWriter, detector *anticheat.Detector, sessionState *session.SessionState, viewerToken string, keepalive KeepaliveConfig) *WSHandler {
+	return NewWSHandlerWithOptions(pty, recorder, cast, detector, sessionState, viewerToken, keepalive, DefaultCompressionConfig())
 }
 
-// NewWSHandler creates a new WebSocket handler
-func NewWSHandler(pty *terminal.PTY, recorder *terminal.Recorder, detector *anticheat.Detector, sessionState *session.SessionState) *WSHandler {
-	return &WSHandler{
+// NewWSHandlerWithOptions creates a new WebSocket handler with explicit
+// keepalive and compression tuning.
+func NewWSHandlerWithOptions(pty *terminal.PTY, recorder *terminal.Recorder, cast *terminal.CastWriter, detector *anticheat.Detector, sessionState *session.SessionState, viewerToken string, keepalive KeepaliveConfig, compression CompressionConfig) *WSHandler {
+	h := &WSHandler{
 		pty:          pty,
+		chaos:        terminal.NewChaos(pty),
 		recorder:     recorder,
+		cast:         cast,
 		detector:     detector,
 		sessionState: sessionState,
+		hub:          NewSessionHub(),
+		viewerToken:  viewerToken,
+		keepalive:    keepalive,
+		compression:  compression,
 		shutdown:     make(chan struct{}),
 		finishSignal: make(chan struct{}, 1),
 	}
+
+	// Fan out every PTY read to the viewer hub, independent of the
+	// operator bridge below. AddReader only requires the target to
+	// expose Write, matching the existing fan-out contract.
+	pty.AddReader(h.hub)
+
+	return h
+}
+
+// SetCompressionLevel updates the permessage-deflate level
+// (compress/flate range: -2..9, -1 = default) for connections upgraded
+// after the call; an already-open connection keeps the level it was
+// upgraded with.
+func (h *WSHandler) SetCompressionLevel(level int) {
+	h.mu.Lock()
+	h.compression.Level = level
+	h.mu.Unlock()
+}
+
+// CompressionConfig returns the currently active compression tuning.
+func (h *WSHandler) CompressionConfig() CompressionConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.compression
+}
+
+// recordCompressionAttempt updates stats for one outbound frame and
+// reports whether it should be compressed. Frames below MinMessageSizeBytes
+// skip compression - the overhead isn't worth it for small keystroke
+// echoes - and are counted at face value; everything else is compressed
+// and its estimated compressed size is folded into BytesOutEstimate.
+func (h *WSHandler) recordCompressionAttempt(stats *CompressionStats, data []byte) bool {
+	cfg := h.CompressionConfig()
+	stats.BytesIn += int64(len(data))
+
+	if len(data) < cfg.MinMessageSizeBytes {
+		stats.FramesSkipped++
+		stats.BytesOutEstimate += int64(len(data))
+		return false
+	}
+
+	stats.FramesCompressed++
+	stats.BytesOutEstimate += int64(estimateCompressedSize(data, cfg.Level))
+	return true
+}
+
+// estimateCompressedSize compresses data at level purely to measure the
+// resulting size for CompressionStats; it never touches the wire.
+func estimateCompressedSize(data []byte, level int) int {
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, level)
+	if err != nil {
+		return len(data)
+	}
+	fw.Write(data)
+	fw.Close()
+	return buf.Len()
+}
+
+// recordCompressionStats logs one connection's CompressionStats into the
+// recorder event stream (or just the log, if this session has no recorder
+// - e.g. a viewer-only connection to a session that isn't being recorded).
+func (h *WSHandler) recordCompressionStats(label string, stats CompressionStats) {
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	if h.recorder != nil {
+		if err := h.recorder.RecordEvent("compression_stats", string(statsJSON)); err != nil {
+			log.Printf("Failed to record compression stats: %v", err)
+		}
+	}
+	log.Printf("%s compression stats: %s", label, statsJSON)
+}
+
+// startKeepalive configures conn's read limit, initial read deadline, and
+// pong handler, then spawns a goroutine that pings every PingInterval
+// until done or h.shutdown fires. writeMu must be the same mutex guarding
+// every other write to conn, since the ping goroutine writes concurrently
+// with the caller's own read/write loops.
+func (h *WSHandler) startKeepalive(conn *websocket.Conn, writeMu *sync.Mutex, done <-chan struct{}) {
+	conn.SetReadLimit(h.keepalive.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(h.keepalive.PongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(h.keepalive.PongTimeout))
+	})
+
+	go func() {
+		ticker := time.NewTicker(h.keepalive.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				writeMu.Lock()
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.keepalive.PingInterval/2))
+				writeMu.Unlock()
+				if err != nil {
+					return
+				}
+			case <-done:
+				return
+			case <-h.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// Hub returns the read-only viewer broadcast hub.
+func (h *WSHandler) Hub() *SessionHub {
+	return h.hub
+}
+
+// acquireOperator claims the single operator slot, returning false if it
+// is already held by another connection.
+func (h *WSHandler) acquireOperator() bool {
+	h.operatorMu.Lock()
+	defer h.operatorMu.Unlock()
+	if h.operatorConnected {
+		return false
+	}
+	h.operatorConnected = true
+	return true
+}
+
+// releaseOperator frees the operator slot so a later connection (e.g. a
+// reconnect after a dropped connection) can claim it.
+func (h *WSHandler) releaseOperator() {
+	h.operatorMu.Lock()
+	h.operatorConnected = false
+	h.operatorMu.Unlock()
+}
+
+// SetChaosConfig updates the link-degradation simulation applied to the
+// PTY stream (see terminal.Chaos).
+func (h *WSHandler) SetChaosConfig(cfg terminal.ChaosConfig) {
+	h.chaos.SetConfig(cfg)
+}
+
+// ChaosConfig returns the currently active chaos simulation settings.
+func (h *WSHandler) ChaosConfig() terminal.ChaosConfig {
+	return h.chaos.Config()
 }
 
 // Shutdown signals all connections to close
@@ -73,8 +475,35 @@ func (h *WSHandler) FinishSignal() <-chan struct{} {
 	return h.finishSignal
 }
 
-// Handle handles WebSocket upgrade and communication
+// TriggerFinish requests session finish from outside the normal client
+// "finish" message or shell-exit paths, e.g. an anti-cheat auto-termination
+// policy watching detector.LiveUpdates().
+func (h *WSHandler) TriggerFinish() {
+	select {
+	case h.finishSignal <- struct{}{}:
+	default:
+		// Already signaled
+	}
+}
+
+// Handle handles WebSocket upgrade and communication. Only one caller can
+// hold the operator slot at a time (first-come-wins); concurrent callers
+// are rejected with 409 so two operators can never race writes to the
+// same PTY. Read-only spectators go through HandleWatch instead.
 func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if ok, reason := h.authorizeUpgrade(r, false); !ok {
+		h.logAuthRejection(r, reason)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if !h.acquireOperator() {
+		log.Printf("Rejecting operator connection from %s: operator slot already taken", r.RemoteAddr)
+		http.Error(w, "operator already connected", http.StatusConflict)
+		return
+	}
+	defer h.releaseOperator()
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade connection: %v", err)
@@ -82,6 +511,13 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
+	conn.EnableWriteCompression(true)
+	if err := conn.SetCompressionLevel(h.CompressionConfig().Level); err != nil {
+		log.Printf("Failed to set compression level: %v", err)
+	}
+	var compStats CompressionStats
+	defer func() { h.recordCompressionStats(fmt.Sprintf("Operator %s", r.RemoteAddr), compStats) }()
+
 	log.Printf("WebSocket connected: %s", r.RemoteAddr)
 
 	// Mark connection in session state
@@ -96,11 +532,15 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	// Create channels for coordination
 	done := make(chan struct{})
 
+	// Detect a dead connection (NAT timeout, crash, network loss) via
+	// ping/pong instead of waiting for a write to eventually fail.
+	h.startKeepalive(conn, &writeMu, done)
+
 	// PTY -> WebSocket (read from PTY, write to WebSocket)
 	go func() {
 		buf := make([]byte, 8192)
 		for {
-			n, err := h.pty.Read(buf)
+			n, err := h.chaos.Read(buf)
 			if err != nil {
 				// PTY closed - likely shell exited
 				log.Printf("PTY closed (shell exited): %v", err)
@@ -117,6 +557,8 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 				conn.WriteMessage(websocket.TextMessage, msgBytes)
 				writeMu.Unlock()
 
+				h.hub.Broadcast(HubEvent{Type: "session_ended", Data: []byte(`{"reason":"shell_exited"}`)})
+
 				// Give client time to receive message
 				time.Sleep(500 * time.Millisecond)
 
@@ -144,6 +586,11 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 						log.Printf("Failed to record output: %v", err)
 					}
 				}
+				if h.cast != nil {
+					if err := h.cast.RecordOutput(buf[:n]); err != nil {
+						log.Printf("Failed to record cast output: %v", err)
+					}
+				}
 
 				// Update terminal buffer for reconnection
 				if h.sessionState != nil {
@@ -151,6 +598,7 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 				}
 
 				writeMu.Lock()
+				conn.EnableWriteCompression(h.recordCompressionAttempt(&compStats, buf[:n]))
 				err := conn.WriteMessage(websocket.TextMessage, buf[:n])
 				writeMu.Unlock()
 
@@ -219,10 +667,11 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 						// Log violations
 						for _, violation := range violations {
+							eventJSON, _ := json.Marshal(violation)
 							if h.recorder != nil {
-								eventJSON, _ := json.Marshal(violation)
 								h.recorder.RecordEvent("anticheat_violation", string(eventJSON))
 							}
+							h.hub.Broadcast(HubEvent{Type: "anticheat", Data: eventJSON})
 						}
 					}
 
@@ -232,8 +681,13 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 							log.Printf("Failed to record input: %v", err)
 						}
 					}
+					if h.cast != nil {
+						if err := h.cast.RecordInput(data); err != nil {
+							log.Printf("Failed to record cast input: %v", err)
+						}
+					}
 
-					if _, err := h.pty.Write(data); err != nil {
+					if _, err := h.chaos.Write(data); err != nil {
 						log.Printf("PTY write error: %v", err)
 						select {
 						case <-done:
@@ -252,9 +706,14 @@ func (h *WSHandler) Handle(w http.ResponseWriter, r *http.Request) {
 						log.Printf("Failed to record input: %v", err)
 					}
 				}
+				if h.cast != nil {
+					if err := h.cast.RecordInput(data); err != nil {
+						log.Printf("Failed to record cast input: %v", err)
+					}
+				}
 
 				// Write binary data directly to PTY
-				if _, err := h.pty.Write(data); err != nil {
+				if _, err := h.chaos.Write(data); err != nil {
 					log.Printf("PTY write error: %v", err)
 					select {
 					case <-done:
@@ -298,6 +757,8 @@ func (h *WSHandler) handleMessage(msg *Message) error {
 			h.sessionState.UpdateTerminalSize(resize.Cols, resize.Rows)
 		}
 
+		h.hub.Broadcast(HubEvent{Type: "resize", Data: msg.Data})
+
 		return h.pty.Resize(resize.Cols, resize.Rows)
 
 	case "finish":
@@ -342,3 +803,283 @@ func (h *WSHandler) handleMessage(msg *Message) error {
 
 	return nil
 }
+
+// maxViewerBacklog bounds how many unread frames a spectator can fall
+// behind before being dropped; the candidate's session must never stall
+// waiting on a slow viewer.
+const maxViewerBacklog = 64
+
+// HubEvent is one typed frame broadcast to every SessionHub subscriber.
+// Both HandleWatch (WebSocket viewers) and HandleEvents (SSE clients) read
+// from the same subscriber channel and translate HubEvent into their own
+// wire format, so PTY output, resizes, anti-cheat violations, and the
+// session-ended notice all flow through one broadcast path.
+type HubEvent struct {
+	Type string // "output", "resize", "anticheat", or "session_ended"
+	Data []byte
+}
+
+// SessionHub fans out session events to read-only spectators. It never
+// touches the PTY itself, so a stuck or slow viewer can only hurt itself.
+type SessionHub struct {
+	mu      sync.Mutex
+	viewers map[string]chan HubEvent
+	nextID  int
+}
+
+// NewSessionHub creates an empty broadcast hub.
+func NewSessionHub() *SessionHub {
+	return &SessionHub{
+		viewers: make(map[string]chan HubEvent),
+	}
+}
+
+// Write implements the io.Writer half of PTY.AddReader's fan-out contract
+// by broadcasting the chunk to every registered viewer as an "output" event.
+func (h *SessionHub) Write(p []byte) (int, error) {
+	h.Broadcast(HubEvent{Type: "output", Data: p})
+	return len(p), nil
+}
+
+// Read satisfies io.Reader so SessionHub can be registered via
+// PTY.AddReader; the PTY never actually reads from a hub.
+func (h *SessionHub) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+// Subscribe registers a new viewer and returns its ID and event channel.
+func (h *SessionHub) Subscribe() (string, <-chan HubEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	id := fmt.Sprintf("viewer-%d", h.nextID)
+	ch := make(chan HubEvent, maxViewerBacklog)
+	h.viewers[id] = ch
+	return id, ch
+}
+
+// Unsubscribe removes a viewer and closes its channel.
+func (h *SessionHub) Unsubscribe(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if ch, ok := h.viewers[id]; ok {
+		delete(h.viewers, id)
+		close(ch)
+	}
+}
+
+// Broadcast sends an event to every viewer, dropping it for any viewer
+// whose channel is full instead of blocking the PTY read loop.
+func (h *SessionHub) Broadcast(event HubEvent) {
+	chunk := make([]byte, len(event.Data))
+	copy(chunk, event.Data)
+	event.Data = chunk
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, ch := range h.viewers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("SessionHub: dropping %s frame for slow viewer %s", event.Type, id)
+		}
+	}
+}
+
+// Count returns the number of currently connected viewers.
+func (h *SessionHub) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.viewers)
+}
+
+// HandleWatch upgrades a spectator connection, replays the current terminal
+// buffer, then streams every subsequent PTY frame. Input frames from the
+// viewer side are always ignored.
+func (h *WSHandler) HandleWatch(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	viewerTokenOK := h.viewerToken != "" && token == h.viewerToken
+
+	if ok, reason := h.authorizeUpgrade(r, viewerTokenOK); !ok {
+		h.logAuthRejection(r, reason)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if !viewerTokenOK {
+		http.Error(w, "Invalid or missing viewer token", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade viewer connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	conn.EnableWriteCompression(true)
+	if err := conn.SetCompressionLevel(h.CompressionConfig().Level); err != nil {
+		log.Printf("Failed to set compression level: %v", err)
+	}
+	var compStats CompressionStats
+	defer func() { h.recordCompressionStats(fmt.Sprintf("Viewer %s", r.RemoteAddr), compStats) }()
+
+	log.Printf("Viewer connected: %s", r.RemoteAddr)
+
+	var writeMu sync.Mutex
+	viewerDone := make(chan struct{})
+	defer close(viewerDone)
+
+	// Detect a dead viewer connection the same way Handle does, instead
+	// of relying on a future write to eventually fail.
+	h.startKeepalive(conn, &writeMu, viewerDone)
+
+	id, events := h.hub.Subscribe()
+	defer h.hub.Unsubscribe(id)
+
+	// Send a snapshot of the current screen so late joiners aren't lost.
+	if h.sessionState != nil {
+		if snapshot := h.sessionState.GetReplayFrame(); len(snapshot) > 0 {
+			writeMu.Lock()
+			err := conn.WriteMessage(websocket.TextMessage, snapshot)
+			writeMu.Unlock()
+			if err != nil {
+				log.Printf("Viewer snapshot write error: %v", err)
+				return
+			}
+		}
+	}
+
+	// Drain (and discard) anything the viewer sends so the connection
+	// doesn't hang reading a full control buffer; viewers never write to
+	// the PTY.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := h.writeViewerEvent(conn, &writeMu, event, &compStats); err != nil {
+				log.Printf("Viewer write error: %v", err)
+				return
+			}
+		case <-h.shutdown:
+			return
+		}
+	}
+}
+
+// writeViewerEvent writes a HubEvent to a WebSocket viewer under writeMu,
+// which also guards the connection's ping writes. "output" frames are
+// written as the raw PTY bytes, unchanged from before HubEvent existed,
+// with compression decided per-frame via stats; other event types are
+// JSON-wrapped with their type, matching the shape SSE clients receive
+// from HandleEvents, and are small enough to always skip compression.
+func (h *WSHandler) writeViewerEvent(conn *websocket.Conn, writeMu *sync.Mutex, event HubEvent, stats *CompressionStats) error {
+	var payload []byte
+	compress := false
+	if event.Type == "output" {
+		payload = event.Data
+		compress = h.recordCompressionAttempt(stats, payload)
+	} else {
+		var err error
+		payload, err = json.Marshal(Message{Type: event.Type, Data: event.Data})
+		if err != nil {
+			return err
+		}
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	conn.EnableWriteCompression(compress)
+	return conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+// HandleEvents streams the same SessionHub broadcast HandleWatch serves
+// over WebSocket as Server-Sent Events instead, for clients that can't or
+// shouldn't use WebSockets - curl-based tailing, browsers behind
+// restrictive proxies, dashboards, CI log tailers. SSE is unidirectional,
+// so unlike HandleWatch this never reads from the connection.
+func (h *WSHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	viewerTokenOK := h.viewerToken != "" && token == h.viewerToken
+
+	if allowed, reason := h.authorizeUpgrade(r, viewerTokenOK); !allowed {
+		h.logAuthRejection(r, reason)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if !viewerTokenOK {
+		http.Error(w, "Invalid or missing viewer token", http.StatusUnauthorized)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	log.Printf("SSE viewer connected: %s", r.RemoteAddr)
+
+	id, events := h.hub.Subscribe()
+	defer h.hub.Unsubscribe(id)
+
+	if h.sessionState != nil {
+		if snapshot := h.sessionState.GetReplayFrame(); len(snapshot) > 0 {
+			if err := writeSSEEvent(w, "output", base64.StdEncoding.EncodeToString(snapshot)); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data := string(event.Data)
+			if event.Type == "output" {
+				data = base64.StdEncoding.EncodeToString(event.Data)
+			}
+			if err := writeSSEEvent(w, event.Type, data); err != nil {
+				log.Printf("SSE write error: %v", err)
+				return
+			}
+			flusher.Flush()
+		case <-h.shutdown:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSEEvent writes one Server-Sent Events frame: an "event:" line
+// naming the HubEvent type, then a single "data:" line. Callers must pass
+// a payload with no raw newlines - base64 for binary PTY output, compact
+// JSON for structured events - since SSE frames are newline-delimited.
+func writeSSEEvent(w io.Writer, eventType, data string) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+	return err
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akonyukov/echobox/internal/session"
+)
+
+func TestOriginPolicyAllowed(t *testing.T) {
+	cases := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    bool
+	}{
+		{"empty policy allows everything", nil, "https://evil.example", true},
+		{"exact match", []string{"https://good.example"}, "https://good.example", true},
+		{"mismatch rejected", []string{"https://good.example"}, "https://evil.example", false},
+		{"wildcard suffix match", []string{"*.good.example"}, "https://sub.good.example", true},
+		{"wildcard suffix mismatch", []string{"*.good.example"}, "https://sub.evil.example", false},
+		{"empty origin rejected when policy set", []string{"https://good.example"}, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := OriginPolicy{Origins: tc.origins}
+			if got := p.Allowed(tc.origin); got != tc.want {
+				t.Fatalf("Allowed(%q) with Origins=%v = %v, want %v", tc.origin, tc.origins, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReconnectTokenAuthenticator(t *testing.T) {
+	state := session.NewSessionState(time.Hour, 0)
+	auth := NewReconnectTokenAuthenticator(state)
+
+	if auth.Authenticate("") {
+		t.Fatalf("empty token should never authenticate")
+	}
+	if auth.Authenticate("not-the-token") {
+		t.Fatalf("wrong token should not authenticate")
+	}
+	if !auth.Authenticate(state.GetReconnectToken()) {
+		t.Fatalf("the session's own reconnect token should authenticate")
+	}
+}
+
+// TestAuthorizeUpgradeViewerTokenBypassesAuthenticator checks the
+// viewerTokenOK fix: with an Authenticator installed, a request that
+// doesn't satisfy it is rejected unless the caller has already validated
+// a separate viewer token and passes viewerTokenOK=true.
+func TestAuthorizeUpgradeViewerTokenBypassesAuthenticator(t *testing.T) {
+	h := &WSHandler{
+		authenticator: stubAuthenticator(false),
+	}
+
+	if ok, reason := h.authorizeUpgrade(httptest.NewRequest(http.MethodGet, "/ws", nil), false); ok {
+		t.Fatalf("request with no viewer token and a failing Authenticator should be rejected, got ok=true reason=%q", reason)
+	}
+
+	if ok, reason := h.authorizeUpgrade(httptest.NewRequest(http.MethodGet, "/watch", nil), true); !ok {
+		t.Fatalf("viewerTokenOK=true should bypass the Authenticator check, got rejected: %s", reason)
+	}
+}
+
+func TestAuthorizeUpgradeRejectsDisallowedOrigin(t *testing.T) {
+	h := &WSHandler{
+		originPolicy: OriginPolicy{Origins: []string{"https://good.example"}},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r.Header.Set("Origin", "https://evil.example")
+
+	// Origin policy applies even when viewerTokenOK is true - a viewer
+	// token only substitutes for the Authenticator check, never for the
+	// origin check.
+	if ok, reason := h.authorizeUpgrade(r, true); ok {
+		t.Fatalf("disallowed origin should be rejected regardless of viewerTokenOK, got ok=true reason=%q", reason)
+	}
+}
+
+type stubAuthenticator bool
+
+func (s stubAuthenticator) Authenticate(token string) bool {
+	return bool(s)
+}
@@ -41,10 +41,10 @@ func main() {
 	// Success - lock file exists
 	// Generate verification code from obfuscated pieces (harder to find with strings)
 	// Code is split across multiple variables and concatenated
-	part1 := string([]byte{83, 82, 69, 45})        // "SRE-"
-	part2 := string([]byte{68, 69, 84, 69, 67})    // "DETEC"
-	part3 := string([]byte{84, 73, 86, 69, 45})    // "TIVE-"
-	suffix := fmt.Sprintf("%d%d%d", 4, 2, 7)       // "427"
+	part1 := string([]byte{83, 82, 69, 45})     // "SRE-"
+	part2 := string([]byte{68, 69, 84, 69, 67}) // "DETEC"
+	part3 := string([]byte{84, 73, 86, 69, 45}) // "TIVE-"
+	suffix := fmt.Sprintf("%d%d%d", 4, 2, 7)    // "427"
 
 	verificationCode := part1 + part2 + part3 + suffix
 